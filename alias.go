@@ -0,0 +1,271 @@
+package config
+
+import (
+	"log"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Logger is the interface used to report non-fatal configuration problems,
+// such as the use of deprecated keys. [*log.Logger] satisfies it.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// Deprecation describes a deprecated key that was found in the configuration.
+type Deprecation struct {
+	// Key is the deprecated key (e.g., "db.max_conn").
+	Key string
+	// Replacement is the key that should be used instead. It is empty when
+	// the key was deprecated without a replacement.
+	Replacement string
+	// Source is where the deprecated key was found: "env" or "config".
+	Source string
+}
+
+// String returns a human-readable description of the deprecation.
+func (d Deprecation) String() string {
+	name := d.Key
+	if d.Source == "env" {
+		name = envName(d.Key)
+	}
+	if d.Replacement == "" {
+		return "config: " + d.Source + " key " + name + " is deprecated"
+	}
+	return "config: " + d.Source + " key " + name + " is deprecated, use " + d.Replacement + " instead"
+}
+
+var (
+	aliasMu      sync.RWMutex
+	aliases      atomic.Pointer[map[string][]string] // new key -> old keys, in registration order; copied on write
+	deprecated   map[string]string                   // old key -> replacement (may be empty)
+	deprecations []Deprecation
+	logger       Logger = log.Default()
+)
+
+// Alias registers oldKey as a deprecated name for newKey.
+//
+// Lookups of newKey fall back to oldKey when newKey is not set, so existing
+// deployments keep working while config.yaml and environment variables are
+// migrated. Environment variables always win, so the lookup order becomes:
+//  1. Environment variable for newKey
+//  2. Environment variable for oldKey
+//  3. Config file value for newKey
+//  4. Config file value for oldKey
+//
+// When the configuration is loaded or changed (e.g., with [Set]) while oldKey is
+// set, a one-time warning is written to the logger (see [SetLogger]) and the
+// key is recorded in [Deprecations]. Reads through oldKey do not report it, so
+// register aliases before loading the configuration.
+//
+// Usage:
+//
+//	config.Alias("db.max_conn", "database.pool.max_open")
+//	config.Init()
+//
+//	maxOpen := config.GetInt("database.pool.max_open")  // falls back to db.max_conn
+func Alias(oldKey, newKey string) {
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+
+	registerDeprecated(oldKey, newKey)
+	if newKey == "" || oldKey == newKey {
+		return
+	}
+	table := make(map[string][]string)
+	if old := aliases.Load(); old != nil {
+		if slices.Contains((*old)[newKey], oldKey) {
+			return
+		}
+		maps.Copy(table, *old)
+	}
+	table[newKey] = slices.Concat(table[newKey], []string{oldKey})
+	aliases.Store(&table)
+}
+
+// registerDeprecated marks oldKey as deprecated in favor of replacement.
+// The caller must hold aliasMu.
+func registerDeprecated(oldKey, replacement string) {
+	if deprecated == nil {
+		deprecated = make(map[string]string)
+	}
+	deprecated[oldKey] = replacement
+}
+
+// SetLogger sets the logger used to report deprecation warnings.
+//
+// The default logger is [log.Default]. Passing nil disables the warnings;
+// deprecated usages are still recorded in [Deprecations].
+//
+// Usage:
+//
+//	config.SetLogger(log.New(os.Stderr, "config: ", 0))
+func SetLogger(l Logger) {
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+	logger = l
+}
+
+// Deprecations returns every deprecated key found in the configuration when
+// it was loaded or changed so far, in the order it was first seen.
+//
+// Call it after loading the configuration (e.g., after [Unmarshal]) to print
+// a report of the keys operators still need to migrate.
+//
+// Usage:
+//
+//	for _, d := range config.Deprecations() {
+//	    log.Println(d)
+//	}
+func Deprecations() []Deprecation {
+	aliasMu.RLock()
+	defer aliasMu.RUnlock()
+	return slices.Clone(deprecations)
+}
+
+// aliasesOf returns the deprecated keys registered for key. It does not take
+// locks, since aliases are registered rarely but looked up by every getter.
+func aliasesOf(key string) []string {
+	if table := aliases.Load(); table != nil {
+		return (*table)[key]
+	}
+	return nil
+}
+
+// reportDeprecated records that the deprecated key was found in the given source
+// and logs a warning the first time it happens.
+func reportDeprecated(key, source string) {
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+
+	replacement, ok := deprecated[key]
+	if !ok {
+		return
+	}
+	d := Deprecation{Key: key, Replacement: replacement, Source: source}
+	if slices.Contains(deprecations, d) {
+		return
+	}
+	deprecations = append(deprecations, d)
+	if logger != nil {
+		logger.Printf("%s", d)
+	}
+}
+
+// resetAliases clears all registered aliases and recorded deprecations.
+func resetAliases() {
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+	aliases.Store(nil)
+	deprecated = nil
+	deprecations = nil
+}
+
+// registerStructDeprecations registers aliases for every struct field tagged
+// with `deprecated`. The tag value is either "use <new key>", which registers
+// an alias, or a free-form note, which only marks the key as deprecated.
+// Newly deprecated keys that are set in s are reported.
+//
+//	MaxConn int `yaml:"max_conn" deprecated:"use database.pool.max_open"`
+func registerStructDeprecations(s *Snapshot, v any, prefix string) {
+	registered := false
+	walkStructFields(v, prefix, func(key string, field reflect.StructField) {
+		note, ok := field.Tag.Lookup("deprecated")
+		if !ok {
+			return
+		}
+		aliasMu.RLock()
+		_, known := deprecated[key]
+		aliasMu.RUnlock()
+		registered = registered || !known
+
+		if replacement, ok := strings.CutPrefix(note, "use "); ok {
+			Alias(key, strings.TrimSpace(replacement))
+			return
+		}
+		aliasMu.Lock()
+		registerDeprecated(key, "")
+		aliasMu.Unlock()
+	})
+	if registered {
+		s.reportDeprecations()
+	}
+}
+
+// reportDeprecations reports every deprecated key set in s, once per load
+// rather than on every read
+func (s *Snapshot) reportDeprecations() {
+	data := map[string]any{}
+	for _, l := range s.layers {
+		if l.kind == treeLayer {
+			mergeOver(data, l.data)
+		}
+	}
+	s.reportDeprecatedKeys(data, "")
+}
+
+// reportDeprecatedKeys reports every deprecated key that is set in the
+// environment or present in data, the (sub)tree rooted at prefix.
+func (s *Snapshot) reportDeprecatedKeys(data map[string]any, prefix string) {
+	aliasMu.RLock()
+	keys := make([]string, 0, len(deprecated))
+	for key := range deprecated {
+		keys = append(keys, key)
+	}
+	aliasMu.RUnlock()
+
+	slices.Sort(keys)
+	for _, key := range keys {
+		if s.hasEnv(key) {
+			reportDeprecated(key, "env")
+			continue
+		}
+		rel, ok := relativeKey(key, prefix)
+		if !ok {
+			continue
+		}
+		if _, ok := getPath(data, rel); ok {
+			reportDeprecated(key, "config")
+		}
+	}
+}
+
+// applyAliases fills in keys that are missing from data with the values of
+// their deprecated aliases. data is the (sub)tree rooted at prefix.
+func (s *Snapshot) applyAliases(data map[string]any, prefix string) {
+	var newKeys []string
+	if table := aliases.Load(); table != nil {
+		newKeys = slices.Sorted(maps.Keys(*table))
+	}
+
+	for _, newKey := range newKeys {
+		rel, ok := relativeKey(newKey, prefix)
+		if !ok {
+			continue
+		}
+		if _, ok := getPath(data, rel); ok {
+			continue
+		}
+		if envVal, ok := s.getEnvValue(newKey); ok {
+			var val any = envVal
+			if orig, ok := s.getFromMap(newKey); ok {
+				val = convertEnvToType(envVal, orig)
+			}
+			setPath(data, rel, val)
+		} else if val, ok := s.getFromMap(newKey); ok {
+			setPath(data, rel, deepCopy(val))
+		}
+	}
+}
+
+// relativeKey returns key relative to prefix, or false if key is not under prefix.
+func relativeKey(key, prefix string) (string, bool) {
+	if prefix == "" {
+		return key, true
+	}
+	return strings.CutPrefix(key, prefix+".")
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"testing"
+)
+
+type recordingLogger struct {
+	messages []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...any) {
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func TestAlias(t *testing.T) {
+	t.Run("falls back to old config key", func(t *testing.T) {
+		Reset()
+		l := &recordingLogger{}
+		SetLogger(l)
+		defer SetLogger(log.Default())
+
+		Alias("db.max_conn", "database.pool.max_open")
+		Set("db.max_conn", 20)
+
+		if got := GetInt("database.pool.max_open"); got != 20 {
+			t.Errorf("GetInt(database.pool.max_open) = %v, want %v", got, 20)
+		}
+		if !IsSet("database.pool.max_open") {
+			t.Error("IsSet(database.pool.max_open) = false, want true")
+		}
+
+		// The warning is only logged once.
+		GetInt("database.pool.max_open")
+		if len(l.messages) != 1 {
+			t.Fatalf("logged %d messages, want 1: %v", len(l.messages), l.messages)
+		}
+		want := "config: config key db.max_conn is deprecated, use database.pool.max_open instead"
+		if l.messages[0] != want {
+			t.Errorf("logged %q, want %q", l.messages[0], want)
+		}
+	})
+
+	t.Run("deprecated keys are reported on load", func(t *testing.T) {
+		Reset()
+		l := &recordingLogger{}
+		SetLogger(l)
+		defer SetLogger(log.Default())
+
+		Alias("db.driver", "") // deprecated without a replacement
+		cfg, err := New(WithSources(Map("app", map[string]any{"db.driver": "postgres"})))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if len(l.messages) != 1 {
+			t.Fatalf("logged %v on load, want 1 message", l.messages)
+		}
+
+		// Reading every setting does not report the key again
+		resetAliases()
+		Alias("db.driver", "")
+		cfg.AllSettings()
+		Diff(cfg.Snapshot(), cfg.Snapshot())
+		if len(l.messages) != 1 {
+			t.Errorf("logged %v after AllSettings and Diff, want no new message", l.messages)
+		}
+
+		if err := cfg.Load(context.Background()); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if len(l.messages) != 2 {
+			t.Errorf("logged %v after Load, want a second message", l.messages)
+		}
+	})
+
+	t.Run("new key wins over old key", func(t *testing.T) {
+		Reset()
+		SetLogger(nil)
+		defer SetLogger(log.Default())
+
+		Alias("db.max_conn", "database.pool.max_open")
+		Set("db.max_conn", 20)
+		Set("database.pool.max_open", 50)
+
+		if got := GetInt("database.pool.max_open"); got != 50 {
+			t.Errorf("GetInt(database.pool.max_open) = %v, want %v", got, 50)
+		}
+		// Still reported, since the old key is left in the configuration
+		want := []Deprecation{{Key: "db.max_conn", Replacement: "database.pool.max_open", Source: "config"}}
+		if got := Deprecations(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Deprecations() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("old env var wins over new config key", func(t *testing.T) {
+		Reset()
+		SetLogger(nil)
+		defer SetLogger(log.Default())
+
+		Alias("db.max_conn", "database.pool.max_open")
+		os.Setenv("DB_MAX_CONN", "30")
+		defer os.Unsetenv("DB_MAX_CONN")
+		Set("database.pool.max_open", 50)
+
+		if got := GetInt("database.pool.max_open"); got != 30 {
+			t.Errorf("GetInt(database.pool.max_open) = %v, want %v", got, 30)
+		}
+
+		want := []Deprecation{{Key: "db.max_conn", Replacement: "database.pool.max_open", Source: "env"}}
+		if got := Deprecations(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Deprecations() = %v, want %v", got, want)
+		}
+		if got := want[0].String(); got != "config: env key DB_MAX_CONN is deprecated, use database.pool.max_open instead" {
+			t.Errorf("Deprecation.String() = %q", got)
+		}
+	})
+
+	t.Run("Reset clears aliases", func(t *testing.T) {
+		Reset()
+		SetLogger(nil)
+		defer SetLogger(log.Default())
+
+		Alias("db.max_conn", "database.pool.max_open")
+		Reset()
+		Set("db.max_conn", 20)
+
+		if IsSet("database.pool.max_open") {
+			t.Error("IsSet(database.pool.max_open) = true after Reset, want false")
+		}
+	})
+}
+
+func TestUnmarshalDeprecatedTag(t *testing.T) {
+	type Config struct {
+		DB struct {
+			MaxConn int    `yaml:"max_conn" deprecated:"use database.pool.max_open"`
+			Driver  string `yaml:"driver" deprecated:"postgres is the only supported driver"`
+		} `yaml:"db"`
+		Database struct {
+			Pool struct {
+				MaxOpen int `yaml:"max_open"`
+			} `yaml:"pool"`
+		} `yaml:"database"`
+	}
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		Reset()
+		SetLogger(nil)
+		defer SetLogger(log.Default())
+
+		Set("db.max_conn", 20)
+		Set("db.driver", "postgres")
+
+		var cfg Config
+		if err := Unmarshal(&cfg); err != nil {
+			t.Fatalf("Unmarshal returned error: %v", err)
+		}
+		if cfg.DB.MaxConn != 20 {
+			t.Errorf("cfg.DB.MaxConn = %v, want %v", cfg.DB.MaxConn, 20)
+		}
+		if cfg.Database.Pool.MaxOpen != 20 {
+			t.Errorf("cfg.Database.Pool.MaxOpen = %v, want %v", cfg.Database.Pool.MaxOpen, 20)
+		}
+
+		want := []Deprecation{
+			{Key: "db.max_conn", Replacement: "database.pool.max_open", Source: "config"},
+			{Key: "db.driver", Source: "config"},
+		}
+		got := Deprecations()
+		if len(got) != len(want) {
+			t.Fatalf("Deprecations() = %v, want %v", got, want)
+		}
+		for _, d := range want {
+			found := false
+			for _, g := range got {
+				found = found || g == d
+			}
+			if !found {
+				t.Errorf("Deprecations() = %v, missing %v", got, d)
+			}
+		}
+	})
+
+	t.Run("UnmarshalKey", func(t *testing.T) {
+		Reset()
+		SetLogger(nil)
+		defer SetLogger(log.Default())
+
+		type Pool struct {
+			MaxOpen int `yaml:"max_open"`
+			MaxIdle int `yaml:"max_idle" deprecated:"use database.pool.idle"`
+			Idle    int `yaml:"idle"`
+		}
+
+		Set("database.pool.max_open", 10)
+		Set("database.pool.max_idle", 5)
+
+		var pool Pool
+		if err := UnmarshalKey("database.pool", &pool); err != nil {
+			t.Fatalf("UnmarshalKey returned error: %v", err)
+		}
+		if pool.Idle != 5 {
+			t.Errorf("pool.Idle = %v, want %v", pool.Idle, 5)
+		}
+		if got := GetInt("database.pool.idle"); got != 5 {
+			t.Errorf("GetInt(database.pool.idle) = %v, want %v", got, 5)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/stanza-go/config"
+)
+
+// runDiff prints the keys that differ between two configuration files, e.g.
+// two revisions of config.yaml, or two profiles given as .env files applied
+// on top of the same config.yaml.
+//
+//	stanza-config diff config.yaml config.new.yaml
+//	stanza-config diff -old-env staging.env -new-env prod.env config.yaml config.yaml
+//	stanza-config diff -format json old.yaml new.yaml
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	format := fs.String("format", "text", "output format: text or json")
+	oldEnv := fs.String("old-env", "", ".env file applied on top of the old file")
+	newEnv := fs.String("new-env", "", ".env file applied on top of the new file")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: stanza-config diff [-format text|json] [-old-env file] [-new-env file] <old> <new>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("expected two files")
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	before, err := loadSnapshot(fs.Arg(0), *oldEnv)
+	if err != nil {
+		return err
+	}
+	after, err := loadSnapshot(fs.Arg(1), *newEnv)
+	if err != nil {
+		return err
+	}
+
+	changes := config.Diff(before, after)
+	if *format == "json" {
+		return config.WriteDiffJSON(stdout, changes)
+	}
+	return config.WriteDiff(stdout, changes)
+}
+
+// loadSnapshot loads a YAML file and, if envFile is set, the environment
+// variables of a .env file on top of it. The process environment is ignored.
+func loadSnapshot(file, envFile string) (*config.Snapshot, error) {
+	sources := []config.Source{config.File(file)}
+	if envFile != "" {
+		sources = append(sources, config.Dotenv(envFile))
+	}
+	cfg, err := config.New(config.WithSources(sources...))
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Snapshot(), nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDiff(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return path
+	}
+	oldPath := write("old.yaml", "http:\n  port: 8080\ndatabase:\n  password: old\n")
+	newPath := write("new.yaml", "http:\n  port: 9090\ndatabase:\n  password: new\ncache:\n  ttl: 5m\n")
+	envPath := write("prod.env", "HTTP_PORT=443\n")
+
+	var buf bytes.Buffer
+	stdout = &buf
+	defer func() { stdout = os.Stdout }()
+
+	if err := runDiff([]string{oldPath, newPath}); err != nil {
+		t.Fatalf("runDiff returned error: %v", err)
+	}
+	want := "+ cache.ttl: 5m\n~ database.password: [REDACTED] -> [REDACTED]\n~ http.port: 8080 -> 9090\n"
+	if got := buf.String(); got != want {
+		t.Errorf("runDiff output =\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := runDiff([]string{"-format", "json", "-new-env", envPath, oldPath, oldPath}); err != nil {
+		t.Fatalf("runDiff returned error: %v", err)
+	}
+	if got := buf.String(); !strings.Contains(got, `"key": "http.port"`) || !strings.Contains(got, `"new": 443`) {
+		t.Errorf("runDiff JSON output = %s, want http.port changed to 443", got)
+	}
+
+	if err := runDiff([]string{oldPath}); err == nil {
+		t.Error("runDiff with one file returned nil error")
+	}
+	if err := runDiff([]string{"-format", "xml", oldPath, newPath}); err == nil {
+		t.Error("runDiff with an unknown format returned nil error")
+	}
+}
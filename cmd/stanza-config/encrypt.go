@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/stanza-go/config"
+	"gopkg.in/yaml.v3"
+)
+
+// runEncrypt encrypts the value of a single key of config.yaml in place,
+// using the primary key of the keyring from STANZA_CONFIG_KEY or
+// STANZA_CONFIG_KEY_FILE.
+//
+//	stanza-config encrypt database.password
+//	stanza-config encrypt -value s3cr3t database.password
+func runEncrypt(args []string) error {
+	fs := flag.NewFlagSet("encrypt", flag.ContinueOnError)
+	file := fs.String("file", "config.yaml", "config file to edit")
+	value := fs.String("value", "", "plaintext to encrypt instead of the current value")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: stanza-config encrypt [-file config.yaml] [-value plaintext] <key>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected exactly one key")
+	}
+	key := fs.Arg(0)
+
+	keys, err := config.LoadKeyring()
+	if err != nil {
+		return err
+	}
+	if keys == nil {
+		return fmt.Errorf("neither %s nor %s is set", config.KeyEnv, config.KeyFileEnv)
+	}
+
+	info, err := os.Stat(*file)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	node, err := findNode(&doc, key)
+	if err != nil {
+		return err
+	}
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("%s is not a scalar value", key)
+	}
+	if config.IsEncrypted(node.Value) {
+		return fmt.Errorf("%s is already encrypted", key)
+	}
+
+	plaintext := node.Value
+	if *value != "" {
+		plaintext = *value
+	}
+	encrypted, err := keys.Encrypt(plaintext)
+	if err != nil {
+		return err
+	}
+	node.Value = encrypted
+	node.Tag = "!!str"
+	node.Style = 0
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(*file, buf.Bytes(), info.Mode().Perm())
+}
+
+// runKeygen prints a new random key for STANZA_CONFIG_KEY.
+//
+//	stanza-config keygen -id 2024-06
+func runKeygen(args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	id := fs.String("id", "", "key ID to prefix the key with, for key rotation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	key, err := config.GenerateKey()
+	if err != nil {
+		return err
+	}
+	if *id != "" {
+		key = *id + ":" + key
+	}
+	fmt.Fprintln(stdout, key)
+	return nil
+}
+
+// findNode returns the value node of the given dot notation key in a YAML document.
+func findNode(doc *yaml.Node, key string) (*yaml.Node, error) {
+	node := doc
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	for _, part := range strings.Split(key, ".") {
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("key %s not found", key)
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == part {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("key %s not found", key)
+		}
+		node = next
+	}
+	return node, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stanza-go/config"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRunEncrypt(t *testing.T) {
+	key, err := config.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey returned error: %v", err)
+	}
+	os.Setenv(config.KeyEnv, "v1:"+key)
+	defer os.Unsetenv(config.KeyEnv)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "# database settings\ndatabase:\n  host: localhost\n  password: secret123 # rotate yearly\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := runEncrypt([]string{"-file", path, "database.password"}); err != nil {
+		t.Fatalf("runEncrypt returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), "# rotate yearly") || !strings.Contains(string(data), "host: localhost") {
+		t.Errorf("config.yaml lost content:\n%s", data)
+	}
+
+	var parsed struct {
+		Database struct {
+			Password string `yaml:"password"`
+		} `yaml:"database"`
+	}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("yaml.Unmarshal returned error: %v", err)
+	}
+	keys, _ := config.LoadKeyring()
+	got, err := keys.Decrypt(parsed.Database.Password)
+	if err != nil {
+		t.Fatalf("Decrypt returned error: %v", err)
+	}
+	if got != "secret123" {
+		t.Errorf("decrypted value = %q, want %q", got, "secret123")
+	}
+
+	if err := runEncrypt([]string{"-file", path, "database.password"}); err == nil {
+		t.Error("runEncrypt of an encrypted value returned nil error")
+	}
+	if err := runEncrypt([]string{"-file", path, "database.missing"}); err == nil {
+		t.Error("runEncrypt of a missing key returned nil error")
+	}
+}
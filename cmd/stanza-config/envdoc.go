@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/stanza-go/config"
+)
+
+// runEnvdoc documents the environment variables that override the keys of
+// config.yaml, as a .env file or a Markdown table. Variables that override
+// several keys are flagged.
+//
+//	stanza-config envdoc > .env.example
+//	stanza-config envdoc -format markdown
+func runEnvdoc(args []string) error {
+	fs := flag.NewFlagSet("envdoc", flag.ContinueOnError)
+	dir := fs.String("dir", ".", "directory to look up config.yaml from")
+	format := fs.String("format", "env", "output format: env or markdown")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return errors.New("unexpected arguments")
+	}
+	if *format != "env" && *format != "markdown" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	if _, err := loadEffective(*dir, true); err != nil {
+		return err
+	}
+	vars := config.EnvVars()
+	for _, ev := range vars {
+		if len(ev.Collisions) > 0 {
+			fmt.Fprintf(os.Stderr, "warning: %s overrides both %s and %v\n", ev.Name, ev.Key, ev.Collisions)
+		}
+	}
+
+	if *format == "markdown" {
+		return config.WriteEnvMarkdown(stdout, vars)
+	}
+	return config.WriteEnvExample(stdout, vars)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stanza-go/config"
+)
+
+func TestRunEnvdoc(t *testing.T) {
+	dir := t.TempDir()
+	content := "http:\n  port: 8080\ndatabase:\n  password: secret123\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	defer config.Reset()
+
+	var buf bytes.Buffer
+	stdout = &buf
+	defer func() { stdout = os.Stdout }()
+
+	config.Reset()
+	if err := runEnvdoc([]string{"-dir", dir}); err != nil {
+		t.Fatalf("runEnvdoc returned error: %v", err)
+	}
+	want := "# database.password (string, secret)\nDATABASE_PASSWORD=\n\n# http.port (int)\nHTTP_PORT=8080\n"
+	if got := buf.String(); got != want {
+		t.Errorf("runEnvdoc output =\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	config.Reset()
+	if err := runEnvdoc([]string{"-dir", dir, "-format", "markdown"}); err != nil {
+		t.Fatalf("runEnvdoc returned error: %v", err)
+	}
+	if got := buf.String(); !strings.Contains(got, "| `HTTP_PORT` | `http.port` | int | `8080` |  |") {
+		t.Errorf("runEnvdoc markdown output =\n%s", got)
+	}
+
+	if err := runEnvdoc([]string{"-dir", dir, "-format", "html"}); err == nil {
+		t.Error("runEnvdoc with an unknown format returned nil error")
+	}
+}
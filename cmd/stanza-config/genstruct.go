@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/stanza-go/config"
+	"gopkg.in/yaml.v3"
+)
+
+// runGenStruct generates a Go struct matching config.yaml, for use with
+// config.Unmarshal. With -check, it fails if the file given with -o is not up
+// to date instead of writing it, e.g. in CI.
+//
+//	//go:generate go run github.com/stanza-go/config/cmd/stanza-config gen-struct -package cfg -file ../config.yaml -o config_gen.go
+//	stanza-config gen-struct -package cfg -file config.yaml -o cfg/config_gen.go -check
+func runGenStruct(args []string) error {
+	fs := flag.NewFlagSet("gen-struct", flag.ContinueOnError)
+	file := fs.String("file", "config.yaml", "config file to generate the struct from")
+	pkg := fs.String("package", "config", "package name of the generated file")
+	typeName := fs.String("type", "Config", "name of the generated struct")
+	accessors := fs.Bool("accessors", false, "also generate a typed accessor function for every key")
+	output := fs.String("o", "", "file to write instead of stdout")
+	check := fs.Bool("check", false, "fail if the file given with -o is not up to date")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return errors.New("unexpected arguments")
+	}
+	if *check && *output == "" {
+		return errors.New("-check requires -o")
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	g := &structGen{pkg: *pkg, accessors: *accessors}
+	code, err := g.generate(&doc, *typeName, fmt.Sprintf("gen-struct -package %s -type %s", *pkg, *typeName))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case *check:
+		current, err := os.ReadFile(*output)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(current, code) {
+			return fmt.Errorf("%s is out of date with %s, run stanza-config gen-struct", *output, *file)
+		}
+		return nil
+	case *output != "":
+		return os.WriteFile(*output, code, 0644)
+	default:
+		_, err := stdout.Write(code)
+		return err
+	}
+}
+
+// structGen generates Go code from a YAML document.
+type structGen struct {
+	pkg       string
+	accessors bool
+
+	structs    []*structDef // root first, then in order of appearance
+	getters    []getterDef
+	names      map[string]bool // declared type and function names
+	usesTime   bool
+	usesConfig bool
+}
+
+// structDef is a generated struct type.
+type structDef struct {
+	name   string
+	fields []fieldDef
+}
+
+// fieldDef is a field of a generated struct.
+type fieldDef struct {
+	name string // Go field name
+	typ  string // Go type expression
+	key  string // YAML key
+}
+
+// getterDef is a generated accessor function.
+type getterDef struct {
+	name   string // Go function name
+	typ    string // Go result type
+	getter string // config getter, e.g. GetInt
+	key    string // dot notation key
+}
+
+// scalarGetters maps the Go types inferred for leaves to the config getter
+// returning them.
+var scalarGetters = map[string]string{
+	"string":        "GetString",
+	"bool":          "GetBool",
+	"int":           "GetInt",
+	"float64":       "GetFloat64",
+	"time.Duration": "GetDuration",
+	"config.Secret": "GetSecret",
+	"[]string":      "GetStringSlice",
+	"[]int":         "GetIntSlice",
+}
+
+// generate returns the formatted source of a file declaring the struct
+// typeName for doc.
+func (g *structGen) generate(doc *yaml.Node, typeName, command string) ([]byte, error) {
+	root := doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind == yaml.DocumentNode {
+		root = &yaml.Node{Kind: yaml.MappingNode} // empty file
+	}
+	if resolveAlias(root).Kind != yaml.MappingNode {
+		return nil, errors.New("config file is not a mapping")
+	}
+	g.names = make(map[string]bool)
+	g.inferStruct([]*yaml.Node{root}, typeName, nil, false)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by stanza-config %s; DO NOT EDIT.\n\n", command)
+	fmt.Fprintf(&buf, "package %s\n\n", g.pkg)
+
+	var imports []string // standard library first
+	if g.usesTime {
+		imports = append(imports, `"time"`)
+	}
+	if g.usesConfig || (g.accessors && len(g.getters) > 0) {
+		imports = append(imports, `"github.com/stanza-go/config"`)
+	}
+	if len(imports) > 0 {
+		fmt.Fprintf(&buf, "import (\n%s\n)\n\n", strings.Join(imports, "\n\n"))
+	}
+
+	for _, s := range g.structs {
+		fmt.Fprintf(&buf, "type %s struct {\n", s.name)
+		for _, f := range s.fields {
+			fmt.Fprintf(&buf, "%s %s `yaml:%q`\n", f.name, f.typ, f.key)
+		}
+		fmt.Fprintf(&buf, "}\n\n")
+	}
+
+	if g.accessors {
+		for _, a := range g.getters {
+			fmt.Fprintf(&buf, "// %s returns the value of %s.\n", a.name, a.key)
+			fmt.Fprintf(&buf, "func %s() %s {\nreturn config.%s(%q)\n}\n\n", a.name, a.typ, a.getter, a.key)
+		}
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// inferStruct declares a struct named after name with the union of the fields
+// of the given mappings (several for a list of mappings) and returns its name.
+func (g *structGen) inferStruct(nodes []*yaml.Node, name string, path []string, inList bool) string {
+	s := &structDef{name: g.declare(name)}
+	g.structs = append(g.structs, s)
+
+	var keys []string
+	values := make(map[string][]*yaml.Node)
+	for _, node := range nodes {
+		for _, pair := range mappingPairs(node) {
+			key := pair[0].Value
+			if _, ok := values[key]; !ok {
+				keys = append(keys, key)
+			}
+			values[key] = append(values[key], pair[1])
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, key := range keys {
+		fieldName := goName(key)
+		for i := 2; used[fieldName]; i++ {
+			fieldName = goName(key) + strconv.Itoa(i)
+		}
+		used[fieldName] = true
+
+		fieldPath := append(append([]string(nil), path...), key)
+		typ := g.inferType(values[key], s.name+fieldName, fieldPath, inList)
+		s.fields = append(s.fields, fieldDef{name: fieldName, typ: typ, key: key})
+	}
+	return s.name
+}
+
+// inferType returns the Go type of the given values of a key, declaring
+// nested structs named after name. Accessors are only generated for keys
+// outside of lists.
+func (g *structGen) inferType(nodes []*yaml.Node, name string, path []string, inList bool) string {
+	var mappings, sequences, scalars []*yaml.Node
+	for _, node := range nodes {
+		node = resolveAlias(node)
+		switch node.Kind {
+		case yaml.MappingNode:
+			mappings = append(mappings, node)
+		case yaml.SequenceNode:
+			sequences = append(sequences, node)
+		case yaml.ScalarNode:
+			if node.Tag != "!!null" {
+				scalars = append(scalars, node)
+			}
+		}
+	}
+
+	var typ string
+	switch {
+	case len(mappings) > 0 && len(sequences) == 0 && len(scalars) == 0:
+		return g.inferStruct(mappings, name, path, inList)
+	case len(sequences) > 0 && len(mappings) == 0 && len(scalars) == 0:
+		var items []*yaml.Node
+		for _, seq := range sequences {
+			items = append(items, seq.Content...)
+		}
+		if len(items) == 0 {
+			typ = "[]string"
+		} else {
+			typ = "[]" + g.inferType(items, name+"Item", path, true)
+		}
+	case len(scalars) > 0 && len(mappings) == 0 && len(sequences) == 0:
+		typ = g.scalarType(scalars[0])
+		for _, node := range scalars[1:] {
+			if g.scalarType(node) != typ {
+				typ = "any"
+				break
+			}
+		}
+	default:
+		typ = "any"
+	}
+
+	if getter, ok := scalarGetters[typ]; ok && !inList {
+		g.getters = append(g.getters, getterDef{
+			name:   g.declare(goName(strings.Join(path, "_"))),
+			typ:    typ,
+			getter: getter,
+			key:    strings.Join(path, "."),
+		})
+	}
+	return typ
+}
+
+// declare returns name, or name with a number appended if it is already
+// declared in the generated file.
+func (g *structGen) declare(name string) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+// durationPattern matches values that config.GetDuration parses, e.g. 1h30m
+// or 7d.
+var durationPattern = regexp.MustCompile(`^-?([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h|d|w))+$`)
+
+// scalarType returns the Go type of a YAML scalar.
+func (g *structGen) scalarType(node *yaml.Node) string {
+	switch node.Tag {
+	case "!!int":
+		return "int"
+	case "!!float":
+		return "float64"
+	case "!!bool":
+		return "bool"
+	case "!duration":
+		g.usesTime = true
+		return "time.Duration"
+	case "!base64":
+		return "[]byte"
+	case "!!str", "!env", "!file":
+		if config.IsEncrypted(node.Value) {
+			g.usesConfig = true
+			return "config.Secret"
+		}
+		if durationPattern.MatchString(node.Value) {
+			if _, err := config.ParseDuration(node.Value); err == nil {
+				g.usesTime = true
+				return "time.Duration"
+			}
+		}
+		return "string"
+	default:
+		return "any"
+	}
+}
+
+// mappingPairs returns the key and value nodes of a mapping, with merge keys
+// (<<: *base) expanded.
+func mappingPairs(node *yaml.Node) [][2]*yaml.Node {
+	node = resolveAlias(node)
+	var pairs, merged [][2]*yaml.Node
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Tag == "!!merge" {
+			value = resolveAlias(value)
+			sources := []*yaml.Node{value}
+			if value.Kind == yaml.SequenceNode {
+				sources = value.Content
+			}
+			for _, src := range sources {
+				merged = append(merged, mappingPairs(src)...)
+			}
+			continue
+		}
+		seen[key.Value] = true
+		pairs = append(pairs, [2]*yaml.Node{key, value})
+	}
+	for _, pair := range merged {
+		if !seen[pair[0].Value] {
+			seen[pair[0].Value] = true
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// resolveAlias returns the node an alias (*name) refers to.
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// initialisms are written in upper case in Go names.
+var initialisms = map[string]bool{
+	"API": true, "CPU": true, "DB": true, "DNS": true, "GRPC": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "JWT": true, "SMTP": true, "SQL": true,
+	"SSH": true, "SSL": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true, "UI": true,
+	"URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goName converts a YAML key to an exported Go name, e.g. api_key to APIKey.
+func goName(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, word := range words {
+		upper := strings.ToUpper(word)
+		if initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		if plural, ok := strings.CutSuffix(upper, "S"); ok && initialisms[plural] {
+			b.WriteString(plural + "s") // e.g. IDs
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunGenStruct(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	content := `app:
+  name: demo
+  debug: false
+http:
+  port: 8080
+  read_timeout: 30s
+  hosts: [a, b]
+  ratio: 0.5
+database: &db
+  api_key: ENC[AES256_GCM,data:abc,iv:def,tag:ghi,kid:1]
+replica:
+  <<: *db
+  lag: 2w
+servers:
+  - name: a
+    weight: 1
+  - name: b
+    tls: true
+ids: [1, 2]
+`
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	stdout = &buf
+	defer func() { stdout = os.Stdout }()
+
+	t.Run("struct", func(t *testing.T) {
+		buf.Reset()
+		if err := runGenStruct([]string{"-file", file, "-package", "cfg"}); err != nil {
+			t.Fatalf("runGenStruct returned error: %v", err)
+		}
+		code := buf.String()
+		if _, err := parser.ParseFile(token.NewFileSet(), "config_gen.go", code, 0); err != nil {
+			t.Fatalf("generated code does not parse: %v\n%s", err, code)
+		}
+
+		for _, want := range []string{
+			"// Code generated by stanza-config gen-struct -package cfg -type Config; DO NOT EDIT.",
+			"package cfg",
+			"App      ConfigApp           `yaml:\"app\"`",
+			"Servers  []ConfigServersItem `yaml:\"servers\"`",
+			"IDs      []int               `yaml:\"ids\"`",
+			"Debug bool   `yaml:\"debug\"`",
+			"ReadTimeout time.Duration `yaml:\"read_timeout\"`",
+			"Hosts       []string      `yaml:\"hosts\"`",
+			"Ratio       float64       `yaml:\"ratio\"`",
+			"APIKey config.Secret `yaml:\"api_key\"`",
+			"type ConfigReplica struct {\n\tLag    time.Duration `yaml:\"lag\"`\n\tAPIKey config.Secret `yaml:\"api_key\"`\n}",
+			"type ConfigServersItem struct {\n\tName   string `yaml:\"name\"`\n\tWeight int    `yaml:\"weight\"`\n\tTLS    bool   `yaml:\"tls\"`\n}",
+		} {
+			if !strings.Contains(code, want) {
+				t.Errorf("generated code does not contain %q:\n%s", want, code)
+			}
+		}
+		if strings.Contains(code, "func ") {
+			t.Errorf("generated accessors without -accessors:\n%s", code)
+		}
+	})
+
+	t.Run("accessors", func(t *testing.T) {
+		buf.Reset()
+		if err := runGenStruct([]string{"-file", file, "-package", "cfg", "-accessors"}); err != nil {
+			t.Fatalf("runGenStruct returned error: %v", err)
+		}
+		code := buf.String()
+		for _, want := range []string{
+			"// HTTPPort returns the value of http.port.\nfunc HTTPPort() int {\n\treturn config.GetInt(\"http.port\")\n}",
+			"func HTTPReadTimeout() time.Duration {\n\treturn config.GetDuration(\"http.read_timeout\")\n}",
+			"func ReplicaAPIKey() config.Secret {\n\treturn config.GetSecret(\"replica.api_key\")\n}",
+		} {
+			if !strings.Contains(code, want) {
+				t.Errorf("generated code does not contain %q:\n%s", want, code)
+			}
+		}
+		if strings.Contains(code, "ServersName") {
+			t.Errorf("generated accessors for the items of a list:\n%s", code)
+		}
+	})
+
+	t.Run("check", func(t *testing.T) {
+		output := filepath.Join(dir, "config_gen.go")
+		args := []string{"-file", file, "-package", "cfg", "-o", output}
+		if err := runGenStruct(args); err != nil {
+			t.Fatalf("runGenStruct returned error: %v", err)
+		}
+		if err := runGenStruct(append(args, "-check")); err != nil {
+			t.Errorf("runGenStruct -check of a fresh file returned error: %v", err)
+		}
+
+		if err := os.WriteFile(file, []byte(content+"extra: true\n"), 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer os.WriteFile(file, []byte(content), 0600)
+		if err := runGenStruct(append(args, "-check")); err == nil {
+			t.Error("runGenStruct -check of a stale file returned nil error")
+		}
+	})
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"port":         "Port",
+		"read_timeout": "ReadTimeout",
+		"api-key":      "APIKey",
+		"db_urls":      "DBURLs",
+		"2fa":          "X2fa",
+		"camelCase":    "CamelCase",
+	}
+	for key, want := range tests {
+		if got := goName(key); got != want {
+			t.Errorf("goName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
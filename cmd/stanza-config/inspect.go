@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/stanza-go/config"
+	"gopkg.in/yaml.v3"
+)
+
+// runValidate loads the configuration exactly as config.Init would and
+// reports every error, e.g. in CI before a deployment: the errors of every
+// source, or else of every validator.
+//
+//	stanza-config validate
+//	stanza-config validate -dir deploy/prod
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	dir := fs.String("dir", ".", "directory to look up config.yaml from")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return errors.New("unexpected arguments")
+	}
+
+	if _, err := loadEffective(*dir, true); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "configuration is valid")
+	return nil
+}
+
+// runGet prints the effective value of a key, including keys only set by an
+// environment variable. Sections and lists are printed as YAML.
+//
+//	stanza-config get http.port
+//	stanza-config get -redact database
+func runGet(args []string) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	dir := fs.String("dir", ".", "directory to look up config.yaml from")
+	redact := fs.Bool("redact", false, "print secret values as [REDACTED]")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: stanza-config get [-dir path] [-redact] <key>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected exactly one key")
+	}
+	key := fs.Arg(0)
+
+	settings, err := loadEffective(*dir, *redact)
+	if err != nil {
+		return err
+	}
+
+	if !config.IsSet(key) {
+		return fmt.Errorf("key %s is not set", key)
+	}
+	value, ok := lookup(settings, key)
+	if !ok {
+		// Environment variables of keys missing from the config files
+		value = config.GetString(key)
+		if *redact && config.IsRedacted(key) {
+			value = config.GetSecret(key)
+		}
+	}
+
+	switch value.(type) {
+	case map[string]any, []any:
+		return printYAML(value)
+	default:
+		_, err := fmt.Fprintln(stdout, config.EnvValue(value))
+		return err
+	}
+}
+
+// runPrint prints the effective configuration: config.yaml and its fragments
+// with defaults and environment variable overrides applied.
+//
+//	stanza-config print
+//	stanza-config print -format env -redact
+func runPrint(args []string) error {
+	fs := flag.NewFlagSet("print", flag.ContinueOnError)
+	dir := fs.String("dir", ".", "directory to look up config.yaml from")
+	format := fs.String("format", "yaml", "output format: yaml, json or env")
+	redact := fs.Bool("redact", false, "print secret values as [REDACTED]")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return errors.New("unexpected arguments")
+	}
+	if *format != "yaml" && *format != "json" && *format != "env" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	settings, err := loadEffective(*dir, *redact)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(settings)
+	case "env":
+		return printEnv(settings)
+	default:
+		return printYAML(settings)
+	}
+}
+
+// loadEffective loads the configuration from dir like config.Init and returns
+// its effective settings, with secrets redacted if redact is set. Warnings
+// (e.g., deprecated keys or overwritten keys in config fragments) are printed
+// to stderr.
+func loadEffective(dir string, redact bool) (map[string]any, error) {
+	if err := os.Chdir(dir); err != nil {
+		return nil, err
+	}
+	config.SetLogger(log.New(os.Stderr, "warning: ", 0))
+
+	if err := config.InitContext(context.Background()); err != nil {
+		return nil, err
+	}
+	if redact {
+		return config.AllSettings(), nil
+	}
+	return config.RevealedSettings(), nil
+}
+
+// lookup returns the value of key in settings.
+func lookup(settings map[string]any, key string) (any, bool) {
+	var value any = settings
+	for _, part := range strings.Split(key, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// printYAML prints v as a YAML document.
+func printYAML(v any) error {
+	enc := yaml.NewEncoder(stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// printEnv prints the leaves of settings as KEY=value lines, sorted by
+// environment variable name, in the format of a .env file.
+func printEnv(settings map[string]any) error {
+	var lines []string
+	var walk func(m map[string]any, prefix string)
+	walk = func(m map[string]any, prefix string) {
+		for k, v := range m {
+			key := prefix + k
+			if sub, ok := v.(map[string]any); ok {
+				walk(sub, key+".")
+				continue
+			}
+			lines = append(lines, config.EnvName(key)+"="+config.QuoteEnvValue(config.EnvValue(v)))
+		}
+	}
+	walk(settings, "")
+	slices.Sort(lines)
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(stdout, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stanza-go/config"
+)
+
+func TestInspectCommands(t *testing.T) {
+	dir := t.TempDir()
+	content := "http:\n  port: 8080\n  hosts: [a, b]\ndatabase:\n  password: secret123\n  name: \"my app\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("HTTP_PORT=9090\n"), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Unsetenv("HTTP_PORT")
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	defer config.Reset()
+
+	var buf bytes.Buffer
+	stdout = &buf
+	defer func() { stdout = os.Stdout }()
+
+	run := func(t *testing.T, fn func([]string) error, args ...string) string {
+		t.Helper()
+		config.Reset()
+		buf.Reset()
+		if err := fn(append([]string{"-dir", dir}, args...)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return buf.String()
+	}
+
+	t.Run("validate", func(t *testing.T) {
+		if got := run(t, runValidate); got != "configuration is valid\n" {
+			t.Errorf("output = %q", got)
+		}
+
+		badDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(badDir, "config.yaml"), []byte("http: [unclosed\n"), 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := runValidate([]string{"-dir", badDir}); err == nil {
+			t.Error("runValidate of an invalid config.yaml returned nil error")
+		}
+
+		// Every error is reported, not only the first one
+		if err := os.WriteFile(filepath.Join(badDir, "config.yaml"), []byte("a: secretref://env/TEST_MISSING_A\nb: secretref://env/TEST_MISSING_B\n"), 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		err := runValidate([]string{"-dir", badDir})
+		if err == nil || !strings.Contains(err.Error(), "TEST_MISSING_A") || !strings.Contains(err.Error(), "TEST_MISSING_B") {
+			t.Errorf("runValidate() error = %v, want both missing variables", err)
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		if got := run(t, runGet, "http.port"); got != "9090\n" {
+			t.Errorf("get http.port = %q, want %q", got, "9090\n")
+		}
+		if got := run(t, runGet, "database.password"); got != "secret123\n" {
+			t.Errorf("get database.password = %q, want %q", got, "secret123\n")
+		}
+		if got := run(t, runGet, "-redact", "database"); got != "name: my app\npassword: '[REDACTED]'\n" {
+			t.Errorf("get -redact database = %q", got)
+		}
+		if err := runGet([]string{"-dir", dir, "http.missing"}); err == nil {
+			t.Error("get of a missing key returned nil error")
+		}
+
+		os.Setenv("HTTP_TIMEOUT", "30s")
+		defer os.Unsetenv("HTTP_TIMEOUT")
+		os.Setenv("STRIPE_TOKEN", "tok_123")
+		defer os.Unsetenv("STRIPE_TOKEN")
+		if got := run(t, runGet, "http.timeout"); got != "30s\n" {
+			t.Errorf("get http.timeout = %q, want %q", got, "30s\n")
+		}
+		if got := run(t, runGet, "-redact", "stripe.token"); got != "[REDACTED]\n" {
+			t.Errorf("get -redact stripe.token = %q, want %q", got, "[REDACTED]\n")
+		}
+	})
+
+	t.Run("print", func(t *testing.T) {
+		got := run(t, runPrint, "-format", "env", "-redact")
+		want := "DATABASE_NAME=my app\nDATABASE_PASSWORD=[REDACTED]\nHTTP_HOSTS=a,b\nHTTP_PORT=9090\n"
+		if got != want {
+			t.Errorf("print -format env =\n%s\nwant\n%s", got, want)
+		}
+
+		got = run(t, runPrint, "-format", "json")
+		if !strings.Contains(got, `"password": "secret123"`) || !strings.Contains(got, `"port": 9090`) {
+			t.Errorf("print -format json = %s", got)
+		}
+
+		got = run(t, runPrint)
+		if !strings.Contains(got, "http:\n  hosts:\n    - a\n    - b\n  port: 9090\n") {
+			t.Errorf("print = %s", got)
+		}
+
+		if err := runPrint([]string{"-dir", dir, "-format", "xml"}); err == nil {
+			t.Error("print with an unknown format returned nil error")
+		}
+	})
+}
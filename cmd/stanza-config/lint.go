@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/stanza-go/config"
+)
+
+// runLint reports the keys of config.yaml whose environment variable
+// overrides are ambiguous or unreliable (see config.Lint), and fails if there
+// are any.
+//
+//	stanza-config lint
+//	stanza-config lint -dir deploy/prod
+func runLint(args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	dir := fs.String("dir", ".", "directory to look up config.yaml from")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return errors.New("unexpected arguments")
+	}
+
+	if _, err := loadEffective(*dir, true); err != nil {
+		return err
+	}
+	issues := config.Lint()
+	for _, issue := range issues {
+		fmt.Fprintln(stdout, issue)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("%d issues found", len(issues))
+	}
+	return nil
+}
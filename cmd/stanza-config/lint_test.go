@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stanza-go/config"
+)
+
+func TestRunLint(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	defer config.Reset()
+
+	var buf bytes.Buffer
+	stdout = &buf
+	defer func() { stdout = os.Stdout }()
+
+	lint := func(t *testing.T, content string) error {
+		t.Helper()
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		config.Reset()
+		buf.Reset()
+		return runLint([]string{"-dir", dir})
+	}
+
+	if err := lint(t, "app:\n  service_name: api\n"); err != nil {
+		t.Errorf("runLint of a clean config returned error: %v", err)
+	}
+
+	if err := lint(t, "app:\n  serviceName: api\n"); err == nil {
+		t.Error("runLint of a camelCase key returned nil error")
+	}
+	if got, want := buf.String(), "app.serviceName: key is not snake_case (key-naming)\n"; got != want {
+		t.Errorf("runLint output = %q, want %q", got, want)
+	}
+}
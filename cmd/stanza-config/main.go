@@ -0,0 +1,88 @@
+// Command stanza-config is a companion tool for the config package.
+//
+// Usage:
+//
+//	stanza-config <command> [flags] [args]
+//
+// Commands:
+//
+//	diff        compare two configuration files
+//	encrypt     encrypt a value of config.yaml in place
+//	envdoc      document the environment variables of config.yaml
+//	gen-struct  generate a Go struct matching config.yaml
+//	get         print the effective value of a key
+//	keygen      generate a new encryption key
+//	lint        check the environment variable names of config.yaml
+//	print       print the effective configuration
+//	validate    load the configuration and report errors
+//
+// Run "stanza-config <command> -h" for the flags of a command.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// command is a stanza-config subcommand.
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{
+	"diff":       {"compare two configuration files", runDiff},
+	"encrypt":    {"encrypt a value of config.yaml in place", runEncrypt},
+	"envdoc":     {"document the environment variables of config.yaml", runEnvdoc},
+	"gen-struct": {"generate a Go struct matching config.yaml", runGenStruct},
+	"get":        {"print the effective value of a key", runGet},
+	"keygen":     {"generate a new encryption key", runKeygen},
+	"lint":       {"check the environment variable names of config.yaml", runLint},
+	"print":      {"print the effective configuration", runPrint},
+	"validate":   {"load the configuration and report errors", runValidate},
+}
+
+// stdout is where commands write their output.
+var stdout io.Writer = os.Stdout
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	if name == "-h" || name == "-help" || name == "--help" || name == "help" {
+		usage()
+		return
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "stanza-config: unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "stanza-config %s: %s\n", name, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: stanza-config <command> [flags] [args]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-11s %s\n", name, commands[name].summary)
+	}
+}
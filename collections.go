@@ -0,0 +1,334 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GetStringMapString returns the map of strings associated with the given
+// key. Nested maps are flattened with dot notation keys.
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE, comma-separated key=value pairs)
+//  2. Config file value, with each entry overridable by its own environment variable
+//
+// Returns an empty map if the key is not found.
+//
+// Config file example (config.yaml):
+//
+//	labels:
+//	  team: core
+//	  tier: 1
+//
+// Environment variable override, replacing the whole map:
+//
+//	export LABELS=team=core,tier=2,region=eu
+//
+// Or a single entry present in the config file:
+//
+//	export LABELS_TIER=2
+//
+// Usage:
+//
+//	for name, value := range config.GetStringMapString("labels") {
+//	    span.SetAttributes(attribute.String(name, value))
+//	}
+func GetStringMapString(key string) map[string]string {
+	return std.GetStringMapString(key)
+}
+
+// GetStringMapString is like [GetStringMapString] but reads from c.
+func (c *Config) GetStringMapString(key string) map[string]string {
+	return c.Snapshot().GetStringMapString(key)
+}
+
+// GetStringMapString is like [GetStringMapString] but reads from s.
+func (s *Snapshot) GetStringMapString(key string) map[string]string {
+	result := map[string]string{}
+	if val, ok := s.getEnvValue(key); ok {
+		splitAndTrimPairs(val, func(k, v string) {
+			result[k] = v
+		})
+		return result
+	}
+
+	walkLeaves(s.effectiveSettings(key), "", func(k string, v any) {
+		result[k] = toString(v)
+	})
+	return result
+}
+
+// GetStringMapStringSlice returns the map of string slices associated with
+// the given key. A single value is returned as a slice of one element.
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE, comma-separated key=value pairs)
+//  2. Config file value, with each entry overridable by its own environment variable
+//
+// Returns an empty map if the key is not found.
+//
+// Config file example (config.yaml):
+//
+//	cors:
+//	  origins:
+//	    api:
+//	      - https://a.com
+//	      - https://b.com
+//	    admin: https://admin.a.com
+//
+// Environment variable override, replacing the whole map (repeated keys
+// accumulate):
+//
+//	export CORS_ORIGINS=api=https://a.com,api=https://c.com,admin=https://admin.a.com
+//
+// Or a single entry present in the config file (comma-separated):
+//
+//	export CORS_ORIGINS_API=https://a.com,https://c.com
+//
+// Usage:
+//
+//	origins := config.GetStringMapStringSlice("cors.origins")
+//	allowed := slices.Contains(origins["api"], r.Header.Get("Origin"))
+func GetStringMapStringSlice(key string) map[string][]string {
+	return std.GetStringMapStringSlice(key)
+}
+
+// GetStringMapStringSlice is like [GetStringMapStringSlice] but reads from c.
+func (c *Config) GetStringMapStringSlice(key string) map[string][]string {
+	return c.Snapshot().GetStringMapStringSlice(key)
+}
+
+// GetStringMapStringSlice is like [GetStringMapStringSlice] but reads from s.
+func (s *Snapshot) GetStringMapStringSlice(key string) map[string][]string {
+	result := map[string][]string{}
+	if val, ok := s.getEnvValue(key); ok {
+		splitAndTrimPairs(val, func(k, v string) {
+			result[k] = append(result[k], v)
+		})
+		return result
+	}
+
+	walkLeaves(s.effectiveSettings(key), "", func(k string, v any) {
+		switch v.(type) {
+		case []any, []string:
+			result[k] = toStringSlice(v)
+		default:
+			result[k] = []string{toString(v)}
+		}
+	})
+	return result
+}
+
+// GetSlice returns the list associated with the given key, decoded into a
+// slice of T, typically a struct with `yaml` tags.
+//
+// Lookup order for each value:
+//  1. Environment variable (key and item index converted to UPPER_SNAKE_CASE)
+//  2. Config file value
+//
+// Returns nil if the key is not found, and an error if the value is not a
+// list or cannot be decoded into T.
+//
+// Fields of items are overridden with environment variables named after
+// their index, e.g. UPSTREAMS_0_URL for the url of the first upstream. Items
+// beyond the end of the list are appended, so a list can be defined by
+// environment variables only, but indexes cannot be skipped: GetSlice returns
+// an error for UPSTREAMS_5_URL if no variable sets an item at index 3 or 4.
+// Lists of scalars (e.g. GetSlice[string]) can
+// also be replaced as a whole with a comma-separated environment variable.
+// [Unmarshal] only overrides the fields of items present in the config file.
+//
+// Use [SliceOf] to read from a [Snapshot] or a [Config].
+//
+// Config file example (config.yaml):
+//
+//	upstreams:
+//	  - url: http://10.0.0.1:8080
+//	    weight: 2
+//	  - url: http://10.0.0.2:8080
+//	    weight: 1
+//
+// Environment variable override:
+//
+//	export UPSTREAMS_0_URL=http://10.0.1.1:8080
+//	export UPSTREAMS_2_URL=http://10.0.0.3:8080
+//	export UPSTREAMS_2_WEIGHT=1
+//
+// Usage:
+//
+//	type Upstream struct {
+//	    URL    string `yaml:"url"`
+//	    Weight int    `yaml:"weight"`
+//	}
+//
+//	upstreams, err := config.GetSlice[Upstream]("upstreams")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func GetSlice[T any](key string) ([]T, error) {
+	return SliceOf[T](std.Snapshot(), key)
+}
+
+// SliceOf is like [GetSlice] but reads from s. Use [Config.Snapshot] to read
+// from a Config:
+//
+//	upstreams, err := config.SliceOf[Upstream](cfg.Snapshot(), "upstreams")
+func SliceOf[T any](s *Snapshot, key string) ([]T, error) {
+	t := reflect.TypeFor[T]()
+	items, err := s.listItems(key, t)
+	if err != nil || items == nil {
+		return nil, err
+	}
+
+	var result []T
+	if err := decodeValue(items, &result); err != nil {
+		return nil, fmt.Errorf("config: %s: %w", key, err)
+	}
+	return result, nil
+}
+
+// listItems returns a copy of the list at key with the environment variable
+// overrides of items of type t applied, or nil if the list is not set
+func (s *Snapshot) listItems(key string, t reflect.Type) ([]any, error) {
+	_, scalar := flagKindOfType(t)
+	if val, ok := s.getEnvValue(key); ok && scalar {
+		return parseDefault(val, reflect.SliceOf(t)).([]any), nil
+	}
+
+	var items []any
+	if val, ok := s.getFromMap(key); ok {
+		rv := reflect.ValueOf(val)
+		if rv.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("config: %s: expected a list", key)
+		}
+		items = make([]any, rv.Len())
+		for i := range items {
+			items[i] = deepCopy(rv.Index(i).Interface())
+		}
+	}
+	if !s.envAbove(key) {
+		return items, nil
+	}
+
+	// Leaf fields of the items, by key relative to the item
+	fields := map[string]reflect.Type{}
+	if scalar {
+		fields[""] = t
+	} else {
+		walkStructFields(reflect.New(t).Interface(), "", func(k string, field reflect.StructField) {
+			if _, ok := flagKindOfType(field.Type); ok {
+				fields[k] = field.Type
+			}
+		})
+	}
+
+	for i := 0; ; i++ {
+		itemKey := key + "." + strconv.Itoa(i)
+		found := false
+		for k, ft := range fields {
+			fieldKey := itemKey
+			if k != "" {
+				fieldKey += "." + k
+			}
+			val, ok := s.getEnvValue(fieldKey)
+			if !ok {
+				continue
+			}
+			if i == len(items) {
+				items = append(items, nil)
+			}
+			found = true
+
+			value := parseDefault(val, ft)
+			if k == "" {
+				items[i] = value
+				continue
+			}
+			m, ok := items[i].(map[string]any)
+			if !ok {
+				m = map[string]any{}
+				items[i] = m
+			}
+			setPath(m, k, value)
+		}
+		if !found && i >= len(items) {
+			if name := s.envPastEnd(key, fields, len(items)); name != "" {
+				return nil, fmt.Errorf("config: %s: %s is past the end of the list (%d items), environment variables cannot skip an index", key, name, len(items))
+			}
+			return items, nil
+		}
+	}
+}
+
+// envPastEnd returns the first environment variable overriding a field of an
+// item of the list at key with an index of at least n, or "" if there is none
+func (s *Snapshot) envPastEnd(key string, fields map[string]reflect.Type, n int) string {
+	prefix := envName(key) + "_"
+	var names []string
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		l := s.layers[i]
+		if l.kind != envLayer {
+			if _, ok := l.get(key); ok {
+				break
+			}
+			continue
+		}
+		for _, name := range l.envNames() {
+			rest, ok := strings.CutPrefix(name, prefix)
+			if !ok {
+				continue
+			}
+			index, field, _ := strings.Cut(rest, "_")
+			if i, err := strconv.Atoi(index); err != nil || i < n || strconv.Itoa(i) != index {
+				continue
+			}
+			for k := range fields {
+				if envName(k) == field {
+					names = append(names, name)
+					break
+				}
+			}
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return slices.Min(names)
+}
+
+// envAbove reports whether an environment layer ranks above every other
+// layer setting key, so that environment variables may override its parts
+func (s *Snapshot) envAbove(key string) bool {
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		l := s.layers[i]
+		if l.kind == envLayer {
+			return true
+		}
+		if _, ok := l.get(key); ok {
+			return false
+		}
+	}
+	return false
+}
+
+// splitAndTrimPairs calls fn for every key=value pair of a comma-separated
+// string, trimming whitespace and skipping elements without a key.
+//
+// Examples:
+//
+//	"team=core,tier=1"   -> (team, core), (tier, 1)
+//	"team = core, tier=" -> (team, core), (tier, "")
+//	"team,=x"            -> nothing
+func splitAndTrimPairs(s string, fn func(key, value string)) {
+	for _, part := range splitAndTrimStringSlice(s) {
+		k, v, ok := strings.Cut(part, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			continue
+		}
+		fn(k, strings.TrimSpace(v))
+	}
+}
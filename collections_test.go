@@ -0,0 +1,210 @@
+package config
+
+import (
+	"context"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestStringMapGetters(t *testing.T) {
+	t.Run("GetStringMapString", func(t *testing.T) {
+		Reset()
+		Set("labels", map[string]any{"team": "core", "tier": 1, "owner": map[string]any{"name": "ops"}})
+
+		want := map[string]string{"team": "core", "tier": "1", "owner.name": "ops"}
+		if got := GetStringMapString("labels"); !maps.Equal(got, want) {
+			t.Errorf("GetStringMapString(labels) = %v, want %v", got, want)
+		}
+		if got := GetStringMapString("missing"); got == nil || len(got) != 0 {
+			t.Errorf("GetStringMapString(missing) = %#v, want an empty map", got)
+		}
+
+		os.Setenv("LABELS_TIER", "2")
+		defer os.Unsetenv("LABELS_TIER")
+		want = map[string]string{"team": "core", "tier": "2", "owner.name": "ops"}
+		if got := GetStringMapString("labels"); !maps.Equal(got, want) {
+			t.Errorf("GetStringMapString(labels) with LABELS_TIER = %v, want %v", got, want)
+		}
+
+		os.Setenv("LABELS", "team=core, tier=3,region=eu,invalid,=x")
+		defer os.Unsetenv("LABELS")
+		want = map[string]string{"team": "core", "tier": "3", "region": "eu"}
+		if got := GetStringMapString("labels"); !maps.Equal(got, want) {
+			t.Errorf("GetStringMapString(labels) with LABELS = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("GetStringMapStringSlice", func(t *testing.T) {
+		Reset()
+		Set("cors.origins", map[string]any{
+			"api":   []any{"https://a.com", "https://b.com"},
+			"admin": "https://admin.a.com",
+		})
+
+		got := GetStringMapStringSlice("cors.origins")
+		if !slices.Equal(got["api"], []string{"https://a.com", "https://b.com"}) || !slices.Equal(got["admin"], []string{"https://admin.a.com"}) {
+			t.Errorf("GetStringMapStringSlice(cors.origins) = %v", got)
+		}
+
+		os.Setenv("CORS_ORIGINS_API", "https://c.com, https://d.com")
+		defer os.Unsetenv("CORS_ORIGINS_API")
+		got = GetStringMapStringSlice("cors.origins")
+		if !slices.Equal(got["api"], []string{"https://c.com", "https://d.com"}) {
+			t.Errorf("GetStringMapStringSlice(cors.origins)[api] with CORS_ORIGINS_API = %v", got["api"])
+		}
+
+		os.Setenv("CORS_ORIGINS", "api=https://a.com,api=https://e.com,web=https://w.com")
+		defer os.Unsetenv("CORS_ORIGINS")
+		got = GetStringMapStringSlice("cors.origins")
+		if len(got) != 2 || !slices.Equal(got["api"], []string{"https://a.com", "https://e.com"}) || !slices.Equal(got["web"], []string{"https://w.com"}) {
+			t.Errorf("GetStringMapStringSlice(cors.origins) with CORS_ORIGINS = %v", got)
+		}
+	})
+}
+
+func TestGetSlice(t *testing.T) {
+	type TLS struct {
+		Insecure bool `yaml:"insecure"`
+	}
+	type Upstream struct {
+		URL    string `yaml:"url"`
+		Weight int    `yaml:"weight"`
+		TLS    TLS    `yaml:"tls"`
+	}
+	upstreams := []any{
+		map[string]any{"url": "http://10.0.0.1:8080", "weight": 2},
+		map[string]any{"url": "http://10.0.0.2:8080", "weight": 1},
+	}
+
+	t.Run("config file values", func(t *testing.T) {
+		Reset()
+		Set("upstreams", upstreams)
+
+		got, err := GetSlice[Upstream]("upstreams")
+		want := []Upstream{{URL: "http://10.0.0.1:8080", Weight: 2}, {URL: "http://10.0.0.2:8080", Weight: 1}}
+		if err != nil || !slices.Equal(got, want) {
+			t.Errorf("GetSlice(upstreams) = %+v, %v, want %+v", got, err, want)
+		}
+		if got, err := GetSlice[Upstream]("missing"); got != nil || err != nil {
+			t.Errorf("GetSlice(missing) = %+v, %v, want nil, nil", got, err)
+		}
+	})
+
+	t.Run("environment variables override and append items", func(t *testing.T) {
+		Reset()
+		Set("upstreams", upstreams)
+		for name, value := range map[string]string{
+			"UPSTREAMS_0_URL":          "http://10.0.1.1:8080",
+			"UPSTREAMS_1_TLS_INSECURE": "true",
+			"UPSTREAMS_2_URL":          "http://10.0.0.3:8080",
+			"UPSTREAMS_2_WEIGHT":       "5",
+		} {
+			os.Setenv(name, value)
+			defer os.Unsetenv(name)
+		}
+
+		got, err := GetSlice[Upstream]("upstreams")
+		want := []Upstream{
+			{URL: "http://10.0.1.1:8080", Weight: 2},
+			{URL: "http://10.0.0.2:8080", Weight: 1, TLS: TLS{Insecure: true}},
+			{URL: "http://10.0.0.3:8080", Weight: 5},
+		}
+		if err != nil || !slices.Equal(got, want) {
+			t.Errorf("GetSlice(upstreams) = %+v, %v, want %+v", got, err, want)
+		}
+
+		var cfg struct {
+			Upstreams []Upstream `yaml:"upstreams"`
+		}
+		if err := Unmarshal(&cfg); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		// Unmarshal only overrides fields of items in the config file
+		if len(cfg.Upstreams) != 2 || cfg.Upstreams[0].URL != "http://10.0.1.1:8080" || cfg.Upstreams[1].TLS.Insecure {
+			t.Errorf("Unmarshal() = %+v, want the first URL overridden", cfg.Upstreams)
+		}
+	})
+
+	t.Run("list defined by environment variables only", func(t *testing.T) {
+		Reset()
+		os.Setenv("BACKENDS_0_URL", "http://a")
+		defer os.Unsetenv("BACKENDS_0_URL")
+		os.Setenv("BACKENDS_1_URL", "http://b")
+		defer os.Unsetenv("BACKENDS_1_URL")
+
+		got, err := GetSlice[Upstream]("backends")
+		if err != nil || len(got) != 2 || got[1].URL != "http://b" {
+			t.Errorf("GetSlice(backends) = %+v, %v", got, err)
+		}
+	})
+
+	t.Run("index gaps are reported", func(t *testing.T) {
+		Reset()
+		Set("upstreams", upstreams)
+		os.Setenv("UPSTREAMS_5_URL", "http://10.0.0.6:8080")
+		defer os.Unsetenv("UPSTREAMS_5_URL")
+		os.Setenv("UPSTREAMS_4_TLS_INSECURE", "true")
+		defer os.Unsetenv("UPSTREAMS_4_TLS_INSECURE")
+
+		_, err := GetSlice[Upstream]("upstreams")
+		if err == nil || !strings.Contains(err.Error(), "UPSTREAMS_4_TLS_INSECURE is past the end of the list (2 items)") {
+			t.Errorf("GetSlice(upstreams) error = %v, want UPSTREAMS_4_TLS_INSECURE reported", err)
+		}
+
+		os.Setenv("UPSTREAMS_2_URL", "http://10.0.0.3:8080")
+		defer os.Unsetenv("UPSTREAMS_2_URL")
+		os.Setenv("UPSTREAMS_3_URL", "http://10.0.0.4:8080")
+		defer os.Unsetenv("UPSTREAMS_3_URL")
+		if got, err := GetSlice[Upstream]("upstreams"); err != nil || len(got) != 6 {
+			t.Errorf("GetSlice(upstreams) = %+v, %v, want 6 items", got, err)
+		}
+	})
+
+	t.Run("lists of scalars", func(t *testing.T) {
+		Reset()
+		Set("ports", []any{8080, 8081})
+		os.Setenv("PORTS_1", "9091")
+		defer os.Unsetenv("PORTS_1")
+
+		if got, err := GetSlice[int]("ports"); err != nil || !slices.Equal(got, []int{8080, 9091}) {
+			t.Errorf("GetSlice(ports) = %v, %v, want [8080 9091]", got, err)
+		}
+
+		os.Setenv("PORTS", "1, 2,3")
+		defer os.Unsetenv("PORTS")
+		if got, err := GetSlice[int]("ports"); err != nil || !slices.Equal(got, []int{1, 2, 3}) {
+			t.Errorf("GetSlice(ports) = %v, %v, want [1 2 3]", got, err)
+		}
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		Reset()
+		Set("upstreams", "http://10.0.0.1:8080")
+		if _, err := GetSlice[Upstream]("upstreams"); err == nil {
+			t.Error("GetSlice() of a scalar error = nil, want error")
+		}
+
+		Set("upstreams", []any{map[string]any{"weight": "heavy"}})
+		if _, err := GetSlice[Upstream]("upstreams"); err == nil {
+			t.Error("GetSlice() of an invalid weight error = nil, want error")
+		}
+	})
+
+	t.Run("layers above the environment", func(t *testing.T) {
+		Reset()
+		Set("upstreams", upstreams)
+		os.Setenv("UPSTREAMS_0_URL", "http://env")
+		defer os.Unsetenv("UPSTREAMS_0_URL")
+
+		ctx := WithOverrides(context.Background(), map[string]any{
+			"upstreams": []any{map[string]any{"url": "http://context"}},
+		})
+		got, err := SliceOf[Upstream](FromContext(ctx), "upstreams")
+		if err != nil || len(got) != 1 || got[0].URL != "http://context" {
+			t.Errorf("SliceOf(upstreams) = %+v, %v, want the context value", got, err)
+		}
+	})
+}
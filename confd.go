@@ -0,0 +1,290 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Conflict describes a key of a config fragment that overwrote the value set
+// by config.yaml or by an earlier fragment (see [SetConfDir]).
+type Conflict struct {
+	// Key is the overwritten key (e.g., "database.host").
+	Key string
+	// File is the fragment that set the value in effect.
+	File string
+	// Overwritten is the file whose value was overwritten.
+	Overwritten string
+}
+
+// String returns a human-readable description of the conflict.
+func (c Conflict) String() string {
+	return "config: " + c.File + " overwrites " + c.Key + " set by " + c.Overwritten
+}
+
+var (
+	confDirMu sync.RWMutex
+	confDir   string
+)
+
+// SetConfDir sets the directory of config fragments loaded by [Init], relative
+// to the directory of config.yaml. Passing an empty string (the default)
+// disables fragments.
+//
+// Every *.yaml file of the directory is loaded in lexical order and deep-merged
+// over config.yaml, so later fragments override earlier ones. Keys overwritten
+// by a fragment are logged (see [SetLogger]) and returned by [Conflicts], and
+// [Provenance] reports which file supplies each key. A missing directory is
+// not an error.
+//
+// Directory layout example:
+//
+//	config.yaml
+//	conf.d/
+//	  10-db.yaml
+//	  20-tracing.yaml
+//
+// Usage:
+//
+//	config.SetConfDir("conf.d")
+//	config.Init()
+func SetConfDir(dir string) {
+	confDirMu.Lock()
+	defer confDirMu.Unlock()
+	confDir = dir
+}
+
+// ConfDir returns a [Source] that reads the YAML file at path, then deep-merges
+// every *.yaml file of dir over it in lexical order (see [SetConfDir]).
+// Either path or dir may be empty.
+//
+// Usage:
+//
+//	cfg, err := config.New(config.WithSources(
+//	    config.ConfDir("config.yaml", "conf.d"),
+//	    config.Env(),
+//	))
+func ConfDir(path, dir string) Source {
+	name := path
+	if name == "" {
+		name = dir
+	}
+	return &fileSource{name: name, path: path, dir: dir}
+}
+
+// Conflicts returns the keys overwritten by config fragments during the last
+// load, in the order the fragments were merged.
+//
+// Usage:
+//
+//	for _, c := range config.Conflicts() {
+//	    log.Println(c)  // config: conf.d/20-db.yaml overwrites database.host set by conf.d/10-db.yaml
+//	}
+func Conflicts() []Conflict {
+	return std.Conflicts()
+}
+
+// Conflicts is like [Conflicts] but reports the conflicts of c.
+func (c *Config) Conflicts() []Conflict {
+	return c.Snapshot().Conflicts()
+}
+
+// Conflicts is like [Conflicts] but reports the conflicts of s.
+func (s *Snapshot) Conflicts() []Conflict {
+	return slices.Clone(s.conflicts)
+}
+
+// Provenance reports which file supplies the value of the given key, e.g.
+// "conf.d/10-db.yaml" for a key set by a config fragment (see [SetConfDir]).
+//
+// For values that do not come from a file (environment variables, defaults or
+// values set via [Set]), Provenance returns the layer name, like [Origin].
+// Returns an empty string if the key is not set.
+//
+// Usage:
+//
+//	config.SetConfDir("conf.d")
+//	config.Init()
+//	config.Provenance("database.host")  // returns "/app/conf.d/10-db.yaml"
+func Provenance(key string) string {
+	return std.Provenance(key)
+}
+
+// Provenance is like [Provenance] but reads from c.
+func (c *Config) Provenance(key string) string {
+	return c.Snapshot().Provenance(key)
+}
+
+// Provenance is like [Provenance] but reads from s.
+func (s *Snapshot) Provenance(key string) string {
+	_, l, ok := s.find(key, false)
+	if !ok {
+		return ""
+	}
+
+	for k := key; ; {
+		if file, ok := l.origins[k]; ok {
+			return file
+		}
+		i := strings.LastIndex(k, ".")
+		if i < 0 {
+			return l.name
+		}
+		k = k[:i]
+	}
+}
+
+// originSource is implemented by sources that know which file supplies each
+// of their keys.
+type originSource interface {
+	Source
+	// loadOrigins is like Load, but also returns the file of every leaf key
+	// and the keys overwritten while merging files.
+	loadOrigins(ctx context.Context) (data map[string]any, origins map[string]string, conflicts []Conflict, err error)
+}
+
+func (s *fileSource) loadOrigins(ctx context.Context) (map[string]any, map[string]string, []Conflict, error) {
+	data := make(map[string]any)
+	origins := make(map[string]string)
+	var conflicts []Conflict
+	var keys *Keyring
+	lookup := envLookup(ctx)
+
+	// Includes are confined to the directory of the main file
+	root := s.dir
+	if s.path != "" {
+		root = filepath.Dir(s.path)
+	}
+
+	if s.path != "" {
+		parsed, err := readYAMLFile(s.path, root, lookup, &keys)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		mergeFragment(data, parsed, "", s.path, origins, &conflicts)
+	}
+
+	if s.dir == "" {
+		return data, origins, conflicts, nil
+	}
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.yaml"))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	slices.Sort(files)
+	for _, file := range files {
+		parsed, err := readYAMLFile(file, root, lookup, &keys)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		mergeFragment(data, parsed, "", file, origins, &conflicts)
+	}
+	return data, origins, conflicts, nil
+}
+
+// mergeFragment deep-merges src, the content of file, into dst like [mergeOver],
+// recording the file of every leaf key in origins and every overwritten value
+// in conflicts
+func mergeFragment(dst, src map[string]any, prefix, file string, origins map[string]string, conflicts *[]Conflict) {
+	var found []Conflict
+
+	for k, v := range src {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		existing, exists := dst[k]
+		if dstMap, ok := existing.(map[string]any); ok {
+			if srcMap, ok := v.(map[string]any); ok {
+				mergeFragment(dstMap, srcMap, key, file, origins, conflicts)
+				continue
+			}
+		}
+
+		if exists {
+			var overwritten []string
+			for k, prev := range origins {
+				if (k == key || strings.HasPrefix(k, key+".")) && !slices.Contains(overwritten, prev) {
+					overwritten = append(overwritten, prev)
+				}
+			}
+			forgetOrigins(origins, key)
+			if !reflect.DeepEqual(existing, v) {
+				slices.Sort(overwritten)
+				for _, prev := range overwritten {
+					found = append(found, Conflict{Key: key, File: file, Overwritten: prev})
+				}
+			}
+		}
+
+		dst[k] = deepCopy(v)
+		if m, ok := v.(map[string]any); ok {
+			walkLeaves(m, key, func(key string, _ any) {
+				origins[key] = file
+			})
+		} else {
+			origins[key] = file
+		}
+	}
+
+	slices.SortFunc(found, func(a, b Conflict) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	*conflicts = append(*conflicts, found...)
+}
+
+// forgetOrigins removes key and its subkeys from origins
+func forgetOrigins(origins map[string]string, key string) {
+	for k := range origins {
+		if k == key || strings.HasPrefix(k, key+".") {
+			delete(origins, k)
+		}
+	}
+}
+
+// reportConflicts logs every conflict to the logger (see [SetLogger])
+func reportConflicts(conflicts []Conflict) {
+	aliasMu.RLock()
+	defer aliasMu.RUnlock()
+	if logger == nil {
+		return
+	}
+	for _, c := range conflicts {
+		logger.Printf("%s", c)
+	}
+}
+
+// readYAMLFile parses a YAML file, resolving includes confined to root (see
+// [AllowIncludes]) and custom tags (see tagResolver) with environment
+// variables read by lookup, and decrypting ENC[...] values with the keyring
+// loaded on first use
+func readYAMLFile(path, root string, lookup func(string) (string, bool), keys **Keyring) (map[string]any, error) {
+	includes := newIncludeResolver(root)
+	doc, err := includes.parseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tags := &tagResolver{files: includes.files, lookup: lookup}
+	if err := tags.resolve(doc, path, nil); err != nil {
+		return nil, err
+	}
+
+	var parsed map[string]any
+	if doc.Kind != 0 {
+		if err := doc.Decode(&parsed); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	tags.apply(parsed)
+
+	// Decrypt ENC[...] values
+	if err := decryptValues(parsed, "", lookup, keys); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
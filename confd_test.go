@@ -0,0 +1,138 @@
+package config
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfDir(t *testing.T) {
+	dir := t.TempDir()
+	confDir := filepath.Join(dir, "conf.d")
+	if err := os.Mkdir(confDir, 0755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	configPath := writeTestFile(t, dir, "config.yaml", "app:\n  name: myapp\ndatabase:\n  host: localhost\n  port: 5432\n")
+	dbPath := writeTestFile(t, confDir, "10-db.yaml", "database:\n  host: db.internal\n  port: 5432\n")
+	tracingPath := writeTestFile(t, confDir, "20-tracing.yaml", "tracing:\n  enabled: true\ndatabase:\n  host: db.prod\n")
+	writeTestFile(t, confDir, "README.md", "not: yaml")
+
+	t.Run("fragments are merged in lexical order", func(t *testing.T) {
+		cfg, err := New(WithSources(ConfDir(configPath, confDir)))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		if got := cfg.GetString("database.host"); got != "db.prod" {
+			t.Errorf("GetString(database.host) = %q, want %q", got, "db.prod")
+		}
+		if got := cfg.GetInt("database.port"); got != 5432 {
+			t.Errorf("GetInt(database.port) = %v, want %v", got, 5432)
+		}
+		if got := cfg.GetString("app.name"); got != "myapp" {
+			t.Errorf("GetString(app.name) = %q, want %q", got, "myapp")
+		}
+		if !cfg.GetBool("tracing.enabled") {
+			t.Error("GetBool(tracing.enabled) = false, want true")
+		}
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		l := &recordingLogger{}
+		SetLogger(l)
+		defer SetLogger(log.Default())
+
+		cfg, err := New(WithSources(ConfDir(configPath, confDir)))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		// database.port is set to the same value, which is not a conflict
+		want := []Conflict{
+			{Key: "database.host", File: dbPath, Overwritten: configPath},
+			{Key: "database.host", File: tracingPath, Overwritten: dbPath},
+		}
+		if got := cfg.Conflicts(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Conflicts() = %v, want %v", got, want)
+		}
+		if len(l.messages) != 2 {
+			t.Errorf("logged %v, want 2 lines", l.messages)
+		}
+	})
+
+	t.Run("provenance", func(t *testing.T) {
+		os.Setenv("APP_NAME", "fromenv")
+		defer os.Unsetenv("APP_NAME")
+
+		cfg, err := New(WithSources(ConfDir(configPath, confDir), Env()))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		tests := []struct {
+			key  string
+			want string
+		}{
+			{"database.host", tracingPath},
+			{"database.port", dbPath},
+			{"tracing.enabled", tracingPath},
+			{"app.name", "env"},
+			{"missing", ""},
+		}
+		for _, tt := range tests {
+			if got := cfg.Provenance(tt.key); got != tt.want {
+				t.Errorf("Provenance(%s) = %q, want %q", tt.key, got, tt.want)
+			}
+		}
+
+		// Values set programmatically report the layer name, i.e. the source name
+		cfg.Set("database.host", "override")
+		if got := cfg.Provenance("database.host"); got != configPath {
+			t.Errorf("Provenance(database.host) after Set = %q, want %q", got, configPath)
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		cfg, err := New(WithSources(ConfDir(configPath, filepath.Join(dir, "missing"))))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if got := cfg.GetString("database.host"); got != "localhost" {
+			t.Errorf("GetString(database.host) = %q, want %q", got, "localhost")
+		}
+	})
+
+	t.Run("invalid fragment", func(t *testing.T) {
+		badDir := t.TempDir()
+		writeTestFile(t, badDir, "10-bad.yaml", "database: [unclosed\n")
+
+		if _, err := New(WithSources(ConfDir(configPath, badDir))); err == nil {
+			t.Error("New() error = nil, want error")
+		}
+	})
+
+	t.Run("Init loads the configured directory", func(t *testing.T) {
+		originalDir, _ := os.Getwd()
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer os.Chdir(originalDir)
+
+		Reset()
+		SetConfDir("conf.d")
+		defer SetConfDir("")
+		Init()
+
+		if got := GetString("database.host"); got != "db.prod" {
+			t.Errorf("GetString(database.host) = %q, want %q", got, "db.prod")
+		}
+		if got := Origin("database.host"); got != "config" {
+			t.Errorf("Origin(database.host) = %q, want %q", got, "config")
+		}
+		if got := len(Conflicts()); got != 2 {
+			t.Errorf("len(Conflicts()) = %v, want 2", got)
+		}
+	})
+}
@@ -1,91 +1,522 @@
 package config
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
-
-	"gopkg.in/yaml.v3"
+	"sync/atomic"
 )
 
-// configData holds the parsed YAML configuration
-var (
-	configData map[string]any
-	mu         sync.RWMutex
+// Config holds configuration values merged from an ordered list of layers,
+// from lowest to highest priority. Each layer is loaded from a [Source].
+//
+// The package-level functions (e.g., [GetString]) use the default Config
+// returned by [Default], which is loaded by [Init] and has three layers:
+// defaults (see [SetDefault]), config.yaml and environment variables.
+//
+// Use [New] to create a Config with a custom set of sources.
+//
+// Reads are lock-free: they go through the current [Snapshot], which is
+// replaced atomically on every change.
+type Config struct {
+	mu         sync.Mutex // serializes updates of snap
+	snap       atomic.Pointer[Snapshot]
+	validators []func(*Snapshot) error // run by Load, guarded by mu
+	secrets    secretCache             // resolved secret references, guarded by mu
+
+	watchMu  sync.Mutex
+	watchers []*watcher // called after every update (see Watch)
+}
+
+// layerKind tells how a layer stores its values.
+type layerKind int
+
+const (
+	// treeLayer holds nested values addressed with dot notation keys
+	treeLayer layerKind = iota
+	// envLayer holds environment variables addressed with UPPER_SNAKE_CASE names
+	envLayer
+	// defaultLayer is a treeLayer holding defaults (see [SetDefault])
+	defaultLayer
 )
 
-// Init initializes the configuration by loading .env file (if exists) and config.yaml
-func Init() {
-	path := lookupConfigPath()
+// layer is a single level of configuration values. Layers of published
+// snapshots are never modified.
+type layer struct {
+	name    string
+	kind    layerKind
+	source  Source                      // nil if the layer is not loaded from a source
+	data    map[string]any              // values, by dot notation key or by env var name
+	lookup  func(string) (string, bool) // env var lookup of envLayer layers
+	refs    map[string]string           // secret references resolved in this layer, by key
+	origins map[string]string           // file supplying each leaf key, if loaded from files
+}
 
-	// Load .env file if it exists
-	envPath := filepath.Join(path, ".env")
-	if _, err := os.Stat(envPath); err == nil {
-		if err := loadDotenv(envPath); err != nil {
-			log.Printf("loadDotenv returns error: %s\n", err.Error())
-		}
+// get retrieves the value of exactly the given key from the layer
+func (l *layer) get(key string) (any, bool) {
+	return l.getKey(lookupKey(key))
+}
+
+// getKey is like get, with the forms of the key already computed
+func (l *layer) getKey(info *keyInfo) (any, bool) {
+	if l.kind == envLayer {
+		return l.lookup(info.env)
 	}
+	return getPathParts(l.data, info.path)
+}
 
-	// Load config.yaml
-	configPath := filepath.Join(path, "config.yaml")
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		log.Fatalf("os.ReadFile returns error: %s\n", err.Error())
+// envNames returns the names of the variables of an envLayer layer
+func (l *layer) envNames() []string {
+	if l.data != nil {
+		return slices.Collect(maps.Keys(l.data))
 	}
+	var names []string
+	for _, kv := range os.Environ() {
+		if name, _, ok := strings.Cut(kv, "="); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
 
-	mu.Lock()
-	defer mu.Unlock()
+// std is the default Config used by the package-level functions
+var std = newDefault()
 
-	if err := yaml.Unmarshal(data, &configData); err != nil {
-		log.Fatalf("yaml.Unmarshal returns error: %s\n", err.Error())
+// newDefault returns a Config with empty defaults, config file and environment layers
+func newDefault() *Config {
+	c := &Config{}
+	c.snap.Store(&Snapshot{layers: []*layer{
+		{name: "default", kind: defaultLayer},
+		{name: "config", kind: treeLayer, data: make(map[string]any)},
+		{name: "env", kind: envLayer, lookup: os.LookupEnv},
+	}})
+	return c
+}
+
+// Default returns the default Config used by the package-level functions.
+func Default() *Config {
+	return std
+}
+
+// Option configures a Config created by [New].
+type Option func(*Config)
+
+// WithSources sets the sources of a Config, from lowest to highest priority:
+// values of later sources override values of earlier ones.
+//
+// Usage:
+//
+//	cfg, err := config.New(config.WithSources(
+//	    config.Defaults(map[string]any{"http.port": 8080}),
+//	    config.File("config.yaml"),
+//	    config.Dotenv(".env"),
+//	    config.Env(),
+//	))
+func WithSources(sources ...Source) Option {
+	return func(c *Config) {
+		s := c.snap.Load() // not published yet, see New
+		for _, src := range sources {
+			l := &layer{name: src.Name(), kind: treeLayer, source: src}
+			switch s := src.(type) {
+			case envSource:
+				l.kind = envLayer
+				l.lookup = s.lookupFunc(nil)
+			case *defaultsSource:
+				l.kind = defaultLayer
+			}
+			s.layers = append(s.layers, l)
+		}
 	}
 }
 
-// lookupConfigPath searches for config.yaml starting from the current directory
-// and traversing up to parent directories
-func lookupConfigPath() string {
-	pathHasConfigFile := func(path string) bool {
-		filePath := filepath.Join(path, "config.yaml")
+// New creates a Config from the given options and loads its sources.
+//
+// Precedence is explicit: sources passed to [WithSources] are merged in order,
+// so flags, remote stores or test overrides can be slotted in at any level.
+//
+// Usage:
+//
+//	cfg, err := config.New(config.WithSources(
+//	    config.File("config.yaml"),
+//	    config.Env(),
+//	    config.Map("overrides", map[string]any{"app.debug": true}),
+//	))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	port := cfg.GetInt("http.port")
+func New(opts ...Option) (*Config, error) {
+	c := &Config{}
+	c.snap.Store(&Snapshot{})
+	for _, opt := range opts {
+		opt(c)
+	}
+	if err := c.Load(context.Background()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Load loads every source of the Config again. Values set via [Config.Set]
+// in a layer that is loaded from a source are discarded.
+//
+// Either all layers are replaced or, if a source fails to load or a validator
+// rejects the result (see [AddValidator]), none is. The errors of every
+// source, or else of every validator, are returned together. Watchers (see
+// [WatchConfig]) are notified only if the load succeeds.
+// Reads are not blocked while the sources are loading.
+func (c *Config) Load(ctx context.Context) error {
+	return c.loadWith(ctx, nil)
+}
 
-		_, err := os.Stat(filePath)
+// loadWith is like Load, with the layers of the new snapshot changed by fn
+// first (e.g., to replace their sources). Nothing is published and the
+// watchers are not notified unless the load succeeds.
+func (c *Config) loadWith(ctx context.Context, fn func(next *Snapshot)) (err error) {
+	c.mu.Lock()
+	defer func() {
 		if err == nil {
-			return true
-		} else if os.IsNotExist(err) {
-			return false
+			c.notify() // after c.mu is released
+		}
+	}()
+	defer c.mu.Unlock()
+
+	type loaded struct {
+		data    map[string]any
+		origins map[string]string
+	}
+	next := c.snap.Load().clone()
+	if fn != nil {
+		fn(next)
+	}
+	results := make(map[*layer]loaded, len(next.layers))
+	var conflicts []Conflict
+	var errs []error // of every source, so that all problems are reported at once
+
+	load := func(ctx context.Context, l *layer) {
+		var (
+			data    map[string]any
+			origins map[string]string
+			err     error
+		)
+		if s, ok := l.source.(originSource); ok {
+			var found []Conflict
+			data, origins, found, err = s.loadOrigins(ctx)
+			conflicts = append(conflicts, found...)
 		} else {
-			log.Fatalf("os.Stat returns error: %s (%s)", err.Error(), filePath)
-			return false
+			data, err = l.source.Load(ctx)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("config: load %s: %w", l.name, err))
+			return
+		}
+		results[l] = loaded{data: data, origins: origins}
+	}
+
+	// Load the environment first, so that the other sources (e.g. !env tags
+	// of config files) read the new environment rather than the process one
+	for _, l := range next.layers {
+		if l.source != nil && l.kind == envLayer {
+			load(ctx, l)
+		}
+	}
+	for l, r := range results {
+		if s, ok := l.source.(envSource); ok {
+			l.lookup = s.lookupFunc(r.data)
+		}
+	}
+	envCtx := withEnvLookup(ctx, next.lookupEnv)
+	for _, l := range next.layers {
+		if l.source != nil && l.kind != envLayer {
+			load(envCtx, l)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for l, r := range results {
+		l.data = r.data
+		l.origins = r.origins
+	}
+
+	// Resolve secret references once every layer is loaded, so that
+	// secretref://env/ references see the new environment. Values cached
+	// before are resolved again.
+	secrets := make(secretCache)
+	for _, l := range next.layers {
+		if _, ok := results[l]; !ok || l.kind == envLayer {
+			continue
+		}
+		l.refs = make(map[string]string)
+		if err := resolveSecretRefs(withRefScope(ctx, secrets, next, l), l.data, "", l.refs); err != nil {
+			errs = append(errs, fmt.Errorf("config: load %s: %w", l.name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	next.conflicts = conflicts
+
+	for _, validate := range c.validators {
+		if err := validate(next); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("config: invalid configuration: %w", err)
+	}
+	c.snap.Store(next)
+	c.secrets = secrets
+
+	reportConflicts(conflicts)
+	next.reportDeprecations()
+	return nil
+}
+
+// Init initializes the configuration by loading .env file (if exists) and config.yaml,
+// followed by the config fragments of the directory set with [SetConfDir]
+//
+// Encrypted values (ENC[...], see [Keyring.Encrypt]) are decrypted using the keyring
+// from STANZA_CONFIG_KEY or STANZA_CONFIG_KEY_FILE, which may also be set in .env
+//
+// Secret references (e.g., secretref://file//run/secrets/db, see
+// [RegisterResolver]) are resolved by the registered resolvers
+func Init() {
+	if err := InitContext(context.Background()); err != nil {
+		log.Fatalf("Load returns error: %s\n", err.Error())
+	}
+}
+
+// InitContext is like [Init] but returns an error instead of exiting the
+// process if the configuration cannot be loaded, e.g. to check config.yaml
+// before a deployment.
+//
+// Usage:
+//
+//	if err := config.InitContext(ctx); err != nil {
+//	    fmt.Fprintln(os.Stderr, err)
+//	    os.Exit(1)
+//	}
+func InitContext(ctx context.Context) error {
+	return initDefault(ctx)
+}
+
+// initDefault runs the loading pipeline of Init on the default Config
+func initDefault(ctx context.Context) error {
+	path, err := lookupConfigPath()
+	if err != nil {
+		return err
+	}
+
+	// Load .env file if it exists
+	envPath := filepath.Join(path, ".env")
+	if _, err := os.Stat(envPath); err == nil {
+		if err := loadDotenv(envPath); err != nil {
+			log.Printf("loadDotenv returns error: %s\n", err.Error())
 		}
 	}
 
+	// Load config.yaml and config fragments (see SetConfDir), and take a
+	// snapshot of the environment variables
+	src := &fileSource{name: "config", path: filepath.Join(path, "config.yaml")}
+	confDirMu.RLock()
+	if confDir != "" {
+		src.dir = filepath.Join(path, confDir)
+	}
+	confDirMu.RUnlock()
+	// The sources are replaced in the new snapshot only, which is published
+	// if it loads
+	return std.loadWith(ctx, func(next *Snapshot) {
+		// Find the layers by kind: the snapshot may come from another Config
+		// (see Restore), with a different set of layers
+		next.layerFor(treeLayer).source = src
+		for i := len(next.layers) - 1; i >= 0; i-- {
+			if l := next.layers[i]; l.kind == envLayer {
+				l.source = Env()
+				return
+			}
+		}
+		next.layers = append(next.layers, &layer{name: "env", kind: envLayer, source: Env()})
+	})
+}
+
+// lookupConfigPath searches for config.yaml starting from the current directory
+// and traversing up to parent directories
+func lookupConfigPath() (string, error) {
 	currentPath, err := os.Getwd()
 	if err != nil {
-		log.Fatalf("os.Getwd returns error: %s (%s)", err.Error(), currentPath)
+		return "", fmt.Errorf("config: find config.yaml: %w", err)
 	}
 
 	for {
-		if pathHasConfigFile(currentPath) {
-			return currentPath
+		_, err := os.Stat(filepath.Join(currentPath, "config.yaml"))
+		if err == nil {
+			return currentPath, nil
+		} else if !os.IsNotExist(err) {
+			return "", fmt.Errorf("config: find config.yaml: %w", err)
 		}
 
 		parentPath := filepath.Dir(currentPath)
 		if parentPath == currentPath {
-			return currentPath
+			return currentPath, nil
 		}
 
 		currentPath = parentPath
 	}
 }
 
-// getFromMap retrieves a value from a nested map using dot notation (e.g., "db.host")
-func getFromMap(key string) (any, bool) {
-	mu.RLock()
-	defer mu.RUnlock()
+// find walks the layers from highest to lowest priority and returns the first
+// value of key, along with the layer supplying it. Within each layer, the
+// deprecated aliases of key are tried after key itself (see [Alias]).
+// Environment layers are skipped if skipEnv is set.
+func (s *Snapshot) find(key string, skipEnv bool) (any, *layer, bool) {
+	return s.findKey(lookupKey(key), skipEnv)
+}
 
-	parts := strings.Split(key, ".")
-	var current any = configData
+// findKey is like find, with the forms of the key already computed
+func (s *Snapshot) findKey(info *keyInfo, skipEnv bool) (any, *layer, bool) {
+	oldKeys := aliasesOf(info.key)
+
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		l := s.layers[i]
+		if skipEnv && l.kind == envLayer {
+			continue
+		}
+		if val, ok := l.getKey(info); ok {
+			return val, l, true
+		}
+		for _, oldKey := range oldKeys {
+			if val, ok := l.get(oldKey); ok {
+				return val, l, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// getEnvValue checks for an environment variable override
+// Converts "db.host" -> "DB_HOST"
+// Returns false if a layer ranked above the environment sets the key.
+// Unlike find, it does not box the value, so it does not allocate.
+func (s *Snapshot) getEnvValue(key string) (string, bool) {
+	return s.envValue(lookupKey(key))
+}
+
+// envValue is like getEnvValue, with the forms of the key already computed
+func (s *Snapshot) envValue(info *keyInfo) (string, bool) {
+	oldKeys := aliasesOf(info.key)
+
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		l := s.layers[i]
+		if l.kind != envLayer {
+			if _, ok := l.getKey(info); ok {
+				return "", false
+			}
+			for _, oldKey := range oldKeys {
+				if _, ok := l.get(oldKey); ok {
+					return "", false
+				}
+			}
+			continue
+		}
+
+		if val, ok := l.lookup(info.env); ok {
+			return val, true
+		}
+		for _, oldKey := range oldKeys {
+			if val, ok := l.lookup(envName(oldKey)); ok {
+				return val, true
+			}
+		}
+	}
+	return "", false
+}
+
+// getFromMap retrieves a value from the nested maps of the non-environment
+// layers using dot notation (e.g., "db.host"). Secret values (e.g., decrypted
+// ones) are revealed, so that they convert like plain strings.
+func (s *Snapshot) getFromMap(key string) (any, bool) {
+	val, _, ok := s.find(key, true)
+	return revealValue(val), ok
+}
+
+// hasEnv reports whether an environment layer sets exactly the given key
+func (s *Snapshot) hasEnv(key string) bool {
+	for _, l := range s.layers {
+		if l.kind != envLayer {
+			continue
+		}
+		if _, ok := l.get(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// setInMap sets a value in the nested map using dot notation (e.g., "db.host")
+// The value is stored in the highest tree layer ranked below the environment.
+func (c *Config) setInMap(key string, value any) {
+	c.update(func(next *Snapshot) {
+		next.layerFor(treeLayer).set(key, value)
+	})
+}
+
+// setDefault sets a default value using dot notation (e.g., "db.host")
+func (c *Config) setDefault(key string, value any) {
+	c.update(func(next *Snapshot) {
+		next.layerFor(defaultLayer).set(key, value)
+	})
+}
+
+// layerFor returns the layer that values set programmatically of the given
+// kind are stored in, creating it if needed. s must not be published yet.
+//
+// Defaults are stored in the lowest default layer, which is created at the
+// bottom. Other values are stored in the highest tree layer below the first
+// environment layer, which is created right below it.
+func (s *Snapshot) layerFor(kind layerKind) *layer {
+	if kind == defaultLayer {
+		for _, l := range s.layers {
+			if l.kind == defaultLayer {
+				return l
+			}
+		}
+		l := &layer{name: "default", kind: defaultLayer}
+		s.layers = slices.Insert(s.layers, 0, l)
+		return l
+	}
+
+	pos := len(s.layers)
+	for i, l := range s.layers {
+		if l.kind == envLayer {
+			pos = i
+			break
+		}
+	}
+	if pos > 0 && s.layers[pos-1].kind == treeLayer {
+		return s.layers[pos-1]
+	}
+	l := &layer{name: "config", kind: treeLayer}
+	s.layers = slices.Insert(s.layers, pos, l)
+	return l
+}
+
+// getPath retrieves a value from the given nested map using dot notation
+func getPath(data map[string]any, key string) (any, bool) {
+	return getPathParts(data, strings.Split(key, "."))
+}
+
+// getPathParts is like getPath, with the key already split on dots
+func getPathParts(data map[string]any, parts []string) (any, bool) {
+	var current any = data
 
 	for _, part := range parts {
 		m, ok := current.(map[string]any)
@@ -100,18 +531,11 @@ func getFromMap(key string) (any, bool) {
 	return current, true
 }
 
-// setInMap sets a value in the nested map using dot notation (e.g., "db.host")
-// This is primarily used for testing
-func setInMap(key string, value any) {
-	mu.Lock()
-	defer mu.Unlock()
-
-	if configData == nil {
-		configData = make(map[string]any)
-	}
-
+// setPath sets a value in the given nested map using dot notation,
+// creating intermediate maps as needed
+func setPath(data map[string]any, key string, value any) {
 	parts := strings.Split(key, ".")
-	current := configData
+	current := data
 
 	for i, part := range parts {
 		if i == len(parts)-1 {
@@ -131,3 +555,107 @@ func setInMap(key string, value any) {
 		}
 	}
 }
+
+// expandKeys returns data with dot notation keys (e.g., "http.port") expanded
+// into nested maps
+func expandKeys(data map[string]any) map[string]any {
+	result := make(map[string]any, len(data))
+	for k, v := range data {
+		if m, ok := v.(map[string]any); ok {
+			v = expandKeys(m)
+		}
+		if !strings.Contains(k, ".") {
+			if existing, ok := result[k].(map[string]any); ok {
+				if m, ok := v.(map[string]any); ok {
+					mergeOver(existing, m)
+					continue
+				}
+			}
+			result[k] = v
+			continue
+		}
+		if existing, ok := getPath(result, k); ok {
+			if dst, ok := existing.(map[string]any); ok {
+				if m, ok := v.(map[string]any); ok {
+					mergeOver(dst, m)
+					continue
+				}
+			}
+		}
+		setPath(result, k, v)
+	}
+	return result
+}
+
+// mergeOver copies every key of src into dst, overwriting existing values,
+// recursing into nested maps present in both
+func mergeOver(dst, src map[string]any) {
+	for k, v := range src {
+		if dstMap, ok := dst[k].(map[string]any); ok {
+			if srcMap, ok := v.(map[string]any); ok {
+				mergeOver(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[k] = deepCopy(v)
+	}
+}
+
+// mergeMissing copies every key of src that is missing from dst into dst,
+// recursing into nested maps present in both
+func mergeMissing(dst, src map[string]any) {
+	for k, v := range src {
+		existing, ok := dst[k]
+		if !ok {
+			dst[k] = deepCopy(v)
+			continue
+		}
+		if dstMap, ok := existing.(map[string]any); ok {
+			if srcMap, ok := v.(map[string]any); ok {
+				mergeMissing(dstMap, srcMap)
+			}
+		}
+	}
+}
+
+// walkLeaves calls fn for every value of data that is not a nested map, with
+// its dot notation key prefixed with prefix (if not empty)
+func walkLeaves(data map[string]any, prefix string, fn func(key string, value any)) {
+	for k, v := range data {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if m, ok := v.(map[string]any); ok {
+			walkLeaves(m, key, fn)
+			continue
+		}
+		fn(key, v)
+	}
+}
+
+// deepCopy returns a copy of v in which nested maps and slices are not shared
+func deepCopy(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(val))
+		for k, item := range val {
+			result[k] = deepCopy(item)
+		}
+		return result
+	case []any:
+		result := make([]any, len(val))
+		for i, item := range val {
+			result[i] = deepCopy(item)
+		}
+		return result
+	case []string:
+		return slices.Clone(val)
+	case []int:
+		return slices.Clone(val)
+	case []byte:
+		return slices.Clone(val)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,133 @@
+// Package configtest provides isolated configurations for tests of code using
+// the config package.
+//
+// Unlike [config.Set] and [config.Reset], which modify the default Config,
+// every Config returned by [New] is independent, and environment variables
+// given with [WithEnv] never touch the process environment, so tests can run
+// with t.Parallel():
+//
+//	func TestServer(t *testing.T) {
+//	    t.Parallel()
+//	    cfg := configtest.New(t, `
+//	http:
+//	  port: 8080
+//	`, configtest.WithEnv(map[string]string{"HTTP_PORT": "9000"}))
+//
+//	    srv := NewServer(cfg)  // cfg.GetInt("http.port") == 9000
+//	    // ...
+//	}
+//
+// Registrations such as [config.Alias], [config.RedactKeys] and
+// [config.RegisterResolver] remain global.
+package configtest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stanza-go/config"
+)
+
+// Option configures a Config created by [New].
+type Option func(*options)
+
+type options struct {
+	env      map[string]string
+	defaults map[string]any
+}
+
+// WithEnv sets the environment variables of the Config, by UPPER_SNAKE_CASE
+// name. They are also read by !env tags, secretref://env/ references and the
+// keyring (see [config.KeyEnv]). The process environment is ignored either
+// way.
+func WithEnv(env map[string]string) Option {
+	return func(o *options) {
+		o.env = env
+	}
+}
+
+// WithDefaults sets the default values of the Config (see [config.Defaults]).
+// Keys may use dot notation.
+func WithDefaults(defaults map[string]any) Option {
+	return func(o *options) {
+		o.defaults = defaults
+	}
+}
+
+// New returns a Config loaded from the given YAML document, which is written
+// to a temporary config.yaml so that !include and !file tags resolve relative
+// to t.TempDir(). The test fails immediately if the document cannot be loaded.
+//
+// Usage:
+//
+//	cfg := configtest.New(t, "database:\n  host: localhost\n")
+func New(t testing.TB, yaml string, opts ...Option) *config.Config {
+	t.Helper()
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0600); err != nil {
+		t.Fatalf("configtest: %v", err)
+	}
+
+	var sources []config.Source
+	if o.defaults != nil {
+		sources = append(sources, config.Defaults(o.defaults))
+	}
+	sources = append(sources, config.File(path), config.EnvMap(o.env))
+
+	cfg, err := config.New(config.WithSources(sources...))
+	if err != nil {
+		t.Fatalf("configtest: %v", err)
+	}
+	return cfg
+}
+
+// UseDefault makes the package-level functions of config (e.g.,
+// [config.GetString]) read from cfg until the test ends, then restores the
+// previous configuration, including changes made with [config.Set] during
+// the test.
+//
+// Since the default Config is shared, tests calling UseDefault must not run
+// in parallel with each other.
+//
+// Usage:
+//
+//	configtest.UseDefault(t, configtest.New(t, "app:\n  env: test\n"))
+//	runApp()  // config.GetString("app.env") == "test"
+func UseDefault(t testing.TB, cfg *config.Config) {
+	t.Helper()
+
+	std := config.Default()
+	previous := std.Snapshot()
+	std.Restore(cfg.Snapshot())
+	t.Cleanup(func() {
+		std.Restore(previous)
+	})
+}
+
+// RequireKeys fails the test immediately, listing every missing key, unless
+// all of the given keys are set in cfg (see [config.Config.IsSet]).
+//
+// Usage:
+//
+//	configtest.RequireKeys(t, cfg, "database.host", "database.port")
+func RequireKeys(t testing.TB, cfg *config.Config, keys ...string) {
+	t.Helper()
+
+	var missing []string
+	for _, key := range keys {
+		if !cfg.IsSet(key) {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		t.Fatalf("configtest: required keys are not set: %s", strings.Join(missing, ", "))
+	}
+}
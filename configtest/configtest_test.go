@@ -0,0 +1,151 @@
+package configtest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stanza-go/config"
+)
+
+func TestNew(t *testing.T) {
+	const yaml = "http:\n  port: 8080\n  host: localhost\n"
+
+	for i := 0; i < 4; i++ {
+		port := 9000 + i
+		t.Run(fmt.Sprint(port), func(t *testing.T) {
+			t.Parallel()
+			cfg := New(t, yaml, WithEnv(map[string]string{"HTTP_PORT": fmt.Sprint(port)}))
+
+			if got := cfg.GetInt("http.port"); got != port {
+				t.Errorf("GetInt(http.port) = %d, want %d", got, port)
+			}
+			if got := cfg.GetString("http.host"); got != "localhost" {
+				t.Errorf("GetString(http.host) = %q, want %q", got, "localhost")
+			}
+		})
+	}
+
+	t.Run("ignores the process environment", func(t *testing.T) {
+		os.Setenv("HTTP_HOST", "example.com")
+		defer os.Unsetenv("HTTP_HOST")
+
+		cfg := New(t, yaml)
+		if got := cfg.GetString("http.host"); got != "localhost" {
+			t.Errorf("GetString(http.host) = %q, want %q", got, "localhost")
+		}
+	})
+
+	t.Run("tags and keyring read WithEnv", func(t *testing.T) {
+		key, _ := config.GenerateKey()
+		keys, err := config.ParseKeyring(key)
+		if err != nil {
+			t.Fatalf("ParseKeyring() error = %v", err)
+		}
+		enc, _ := keys.Encrypt("secret123")
+		other, _ := config.GenerateKey()
+
+		os.Setenv("APP_NAME", "fromproc")
+		defer os.Unsetenv("APP_NAME")
+		os.Setenv(config.KeyEnv, other)
+		defer os.Unsetenv(config.KeyEnv)
+
+		cfg := New(t, "app:\n  name: !env APP_NAME\n  pass: "+enc+"\n",
+			WithEnv(map[string]string{"APP_NAME": "fromenv", config.KeyEnv: key}))
+		if got := cfg.GetString("app.name"); got != "fromenv" {
+			t.Errorf("GetString(app.name) = %q, want %q", got, "fromenv")
+		}
+		if got := cfg.GetString("app.pass"); got != "secret123" {
+			t.Errorf("GetString(app.pass) = %q, want %q", got, "secret123")
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		cfg := New(t, yaml, WithDefaults(map[string]any{"http.timeout": "30s", "http.port": 80}))
+		if got := cfg.GetString("http.timeout"); got != "30s" {
+			t.Errorf("GetString(http.timeout) = %q, want %q", got, "30s")
+		}
+		if got := cfg.GetInt("http.port"); got != 8080 {
+			t.Errorf("GetInt(http.port) = %d, want %d", got, 8080)
+		}
+	})
+}
+
+func TestUseDefault(t *testing.T) {
+	config.Reset()
+	config.Set("app.env", "production")
+
+	t.Run("replaces the default Config", func(t *testing.T) {
+		UseDefault(t, New(t, "app:\n  env: test\n"))
+		if got := config.GetString("app.env"); got != "test" {
+			t.Errorf("GetString(app.env) = %q, want %q", got, "test")
+		}
+		config.Set("app.debug", true)
+	})
+
+	if got := config.GetString("app.env"); got != "production" {
+		t.Errorf("GetString(app.env) after the test = %q, want %q", got, "production")
+	}
+	if config.IsSet("app.debug") {
+		t.Error("IsSet(app.debug) after the test = true, want false")
+	}
+}
+
+func TestUseDefaultInit(t *testing.T) {
+	config.Reset()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("app:\n  env: staging\n"), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	originalDir, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	// The Config of UseDefault has no default layer, so the layers of the
+	// default Config are not where InitContext expects them
+	UseDefault(t, New(t, "app:\n  env: test\n"))
+	os.Setenv("APP_NAME", "shop")
+	defer os.Unsetenv("APP_NAME")
+
+	if err := config.InitContext(context.Background()); err != nil {
+		t.Fatalf("InitContext() error = %v", err)
+	}
+	if got := config.GetString("app.env"); got != "staging" {
+		t.Errorf("GetString(app.env) = %q, want %q", got, "staging")
+	}
+	if got := config.GetString("app.name"); got != "shop" {
+		t.Errorf("GetString(app.name) = %q, want %q", got, "shop")
+	}
+}
+
+// fakeT records the failures of a test
+type fakeT struct {
+	testing.TB
+	failures []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Fatalf(format string, args ...any) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func TestRequireKeys(t *testing.T) {
+	cfg := New(t, "database:\n  host: localhost\n", WithEnv(map[string]string{"DATABASE_PORT": "5432"}))
+
+	ft := &fakeT{TB: t}
+	RequireKeys(ft, cfg, "database.host", "database.port")
+	if len(ft.failures) != 0 {
+		t.Errorf("RequireKeys failed: %v", ft.failures)
+	}
+
+	RequireKeys(ft, cfg, "database.host", "database.user", "database.name")
+	want := "configtest: required keys are not set: database.user, database.name"
+	if len(ft.failures) != 1 || ft.failures[0] != want {
+		t.Errorf("RequireKeys failures = %v, want [%s]", ft.failures, want)
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// contextLayer is the name of the layer holding context overrides.
+const contextLayer = "context"
+
+// overridesKey is the context key of the overrides set with WithOverrides.
+type overridesKey struct{}
+
+// overrides are the values set with WithOverrides on a context
+type overrides struct {
+	data map[string]any // expanded, never modified
+
+	// derived caches the last snapshot built from these overrides, with the
+	// snapshot it was built on
+	derived atomic.Pointer[[2]*Snapshot]
+}
+
+// WithOverrides returns a copy of ctx carrying values that override the
+// configuration for reads through [FromContext], e.g. per request, per tenant
+// or per integration test. Keys may use dot notation. Overrides set on a parent
+// context are kept unless overridden again.
+//
+// The shared configuration is not modified.
+//
+// Usage:
+//
+//	ctx = config.WithOverrides(ctx, map[string]any{
+//	    "ratelimit.rps":  100,
+//	    "feature.new_ui": true,
+//	})
+//	handle(ctx)
+func WithOverrides(ctx context.Context, values map[string]any) context.Context {
+	data := make(map[string]any)
+	if parent, ok := ctx.Value(overridesKey{}).(*overrides); ok {
+		data = deepCopy(parent.data).(map[string]any)
+	}
+	mergeOver(data, expandKeys(deepCopy(values).(map[string]any)))
+	return context.WithValue(ctx, overridesKey{}, &overrides{data: data})
+}
+
+// FromContext returns the current [Snapshot] of the configuration, with the
+// overrides set on ctx by [WithOverrides] ranked above every other source,
+// including environment variables.
+//
+// Without overrides, it returns the same Snapshot as [GetSnapshot].
+//
+// Config file example (config.yaml):
+//
+//	ratelimit:
+//	  rps: 10
+//
+// Usage:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//	    rps := config.FromContext(r.Context()).GetInt("ratelimit.rps")  // 100 for premium tenants
+//	    // ...
+//	}
+func FromContext(ctx context.Context) *Snapshot {
+	return std.FromContext(ctx)
+}
+
+// FromContext is like [FromContext] but reads from c.
+func (c *Config) FromContext(ctx context.Context) *Snapshot {
+	base := c.Snapshot()
+	o, ok := ctx.Value(overridesKey{}).(*overrides)
+	if !ok {
+		return base
+	}
+
+	if cached := o.derived.Load(); cached != nil && cached[0] == base {
+		return cached[1]
+	}
+	next := base.clone()
+	next.layers = append(next.layers, &layer{name: contextLayer, kind: treeLayer, data: o.data})
+	o.derived.Store(&[2]*Snapshot{base, next})
+	return next
+}
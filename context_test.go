@@ -0,0 +1,92 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestContextOverrides(t *testing.T) {
+	t.Run("override the configuration and environment", func(t *testing.T) {
+		Reset()
+		Set("ratelimit.rps", 10)
+		Set("ratelimit.burst", 20)
+		os.Setenv("RATELIMIT_BURST", "30")
+		defer os.Unsetenv("RATELIMIT_BURST")
+
+		ctx := WithOverrides(context.Background(), map[string]any{"ratelimit.rps": 100})
+		snap := FromContext(ctx)
+
+		if got := snap.GetInt("ratelimit.rps"); got != 100 {
+			t.Errorf("GetInt(ratelimit.rps) = %d, want %d", got, 100)
+		}
+		if got := snap.GetInt("ratelimit.burst"); got != 30 {
+			t.Errorf("GetInt(ratelimit.burst) = %d, want %d", got, 30)
+		}
+		if got := snap.Origin("ratelimit.rps"); got != "context" {
+			t.Errorf("Origin(ratelimit.rps) = %q, want %q", got, "context")
+		}
+		if got := GetInt("ratelimit.rps"); got != 10 {
+			t.Errorf("GetInt(ratelimit.rps) without context = %d, want %d", got, 10)
+		}
+
+		ctx = WithOverrides(ctx, map[string]any{"ratelimit": map[string]any{"burst": 50}})
+		snap = FromContext(ctx)
+		if rps, burst := snap.GetInt("ratelimit.rps"), snap.GetInt("ratelimit.burst"); rps != 100 || burst != 50 {
+			t.Errorf("nested overrides = %d, %d, want 100, 50", rps, burst)
+		}
+	})
+
+	t.Run("follows changes of the configuration", func(t *testing.T) {
+		Reset()
+		Set("app.name", "v1")
+		ctx := WithOverrides(context.Background(), map[string]any{"app.env": "test"})
+
+		if got := FromContext(ctx).GetString("app.name"); got != "v1" {
+			t.Errorf("GetString(app.name) = %q, want %q", got, "v1")
+		}
+		Set("app.name", "v2")
+		if got := FromContext(ctx).GetString("app.name"); got != "v2" {
+			t.Errorf("GetString(app.name) = %q, want %q", got, "v2")
+		}
+		if FromContext(ctx) != FromContext(ctx) {
+			t.Error("FromContext() built a new Snapshot for an unchanged configuration")
+		}
+	})
+
+	t.Run("without overrides", func(t *testing.T) {
+		Reset()
+		if FromContext(context.Background()) != GetSnapshot() {
+			t.Error("FromContext() != GetSnapshot() without overrides")
+		}
+	})
+
+	t.Run("values are copied", func(t *testing.T) {
+		Reset()
+		values := map[string]any{"app": map[string]any{"env": "test"}}
+		ctx := WithOverrides(context.Background(), values)
+		values["app"].(map[string]any)["env"] = "changed"
+
+		if got := FromContext(ctx).GetString("app.env"); got != "test" {
+			t.Errorf("GetString(app.env) = %q, want %q", got, "test")
+		}
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		Reset()
+		Set("http.port", 8080)
+		Set("http.host", "localhost")
+		ctx := WithOverrides(context.Background(), map[string]any{"http.port": 9000})
+
+		var cfg struct {
+			Port int    `yaml:"port"`
+			Host string `yaml:"host"`
+		}
+		if err := FromContext(ctx).UnmarshalKey("http", &cfg); err != nil {
+			t.Fatalf("UnmarshalKey() error = %v", err)
+		}
+		if cfg.Port != 9000 || cfg.Host != "localhost" {
+			t.Errorf("UnmarshalKey() = %+v, want port 9000 on localhost", cfg)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -13,6 +14,12 @@ func toString(v any) string {
 	switch val := v.(type) {
 	case string:
 		return val
+	case Secret:
+		return string(val)
+	case []byte:
+		return string(val)
+	case time.Duration:
+		return val.String()
 	case int:
 		return strconv.Itoa(val)
 	case int64:
@@ -211,8 +218,8 @@ func toDuration(v any) time.Duration {
 	case float64:
 		return time.Duration(val)
 	case string:
-		// Try parsing as duration string first (e.g., "30s", "1h")
-		if d, err := time.ParseDuration(val); err == nil {
+		// Try parsing as duration string first (e.g., "30s", "1h", "7d", "PT30S")
+		if d, err := ParseDuration(val); err == nil {
 			return d
 		}
 		// Fall back to parsing as integer
@@ -228,7 +235,7 @@ func toDuration(v any) time.Duration {
 func toStringSlice(v any) []string {
 	switch val := v.(type) {
 	case []string:
-		return val
+		return slices.Clone(val)
 	case []any:
 		result := make([]string, len(val))
 		for i, item := range val {
@@ -243,7 +250,7 @@ func toStringSlice(v any) []string {
 func toIntSlice(v any) []int {
 	switch val := v.(type) {
 	case []int:
-		return val
+		return slices.Clone(val)
 	case []any:
 		result := make([]int, len(val))
 		for i, item := range val {
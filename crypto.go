@@ -0,0 +1,310 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Environment variables holding the keys used to decrypt encrypted values.
+const (
+	// KeyEnv holds the keyring itself (see [ParseKeyring]).
+	KeyEnv = "STANZA_CONFIG_KEY"
+	// KeyFileEnv holds the path of a file containing the keyring.
+	KeyFileEnv = "STANZA_CONFIG_KEY_FILE"
+)
+
+const (
+	encPrefix    = "ENC["
+	encSuffix    = "]"
+	encAlgorithm = "AES256_GCM"
+	keySize      = 32
+	nonceSize    = 12
+	tagSize      = 16
+)
+
+// Keyring holds the AES-256 keys used to encrypt and decrypt config values.
+//
+// The first key is the primary key, used by [Keyring.Encrypt]. All keys are
+// tried by [Keyring.Decrypt], which makes key rotation possible: add the new
+// key in front, re-encrypt the values, then remove the old key.
+type Keyring struct {
+	ids  []string
+	keys map[string][]byte
+}
+
+// ParseKeyring parses a keyring from its text form: a comma or newline
+// separated list of base64 encoded 32-byte keys, each optionally prefixed
+// with a key ID and a colon. The first key is the primary key.
+//
+// Examples:
+//
+//	q3JZ9x...base64...=
+//	2024-06:q3JZ9x...base64...=,2023-01:Xk1b...base64...=
+func ParseKeyring(s string) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string][]byte)}
+
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' })
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" || strings.HasPrefix(field, "#") {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(field, ":")
+		if !ok {
+			id, encoded = "", field
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid key %q: %w", id, err)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("config: invalid key %q: must be %d bytes, got %d", id, keySize, len(key))
+		}
+		if _, ok := k.keys[id]; ok {
+			return nil, fmt.Errorf("config: duplicate key %q", id)
+		}
+		k.ids = append(k.ids, id)
+		k.keys[id] = key
+	}
+
+	if len(k.ids) == 0 {
+		return nil, errors.New("config: keyring is empty")
+	}
+	return k, nil
+}
+
+// LoadKeyring loads the keyring from the STANZA_CONFIG_KEY environment
+// variable or, if it is not set, from the file named by STANZA_CONFIG_KEY_FILE.
+//
+// Returns a nil keyring and nil error if neither variable is set. Config files
+// loaded by a [Config] read both variables from its environment sources
+// instead of the process environment.
+func LoadKeyring() (*Keyring, error) {
+	return loadKeyring(os.LookupEnv)
+}
+
+// loadKeyring is like LoadKeyring, with environment variables read by lookup
+func loadKeyring(lookup func(string) (string, bool)) (*Keyring, error) {
+	if s, ok := lookup(KeyEnv); ok {
+		return ParseKeyring(s)
+	}
+	if path, ok := lookup(KeyFileEnv); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return ParseKeyring(string(data))
+	}
+	return nil, nil
+}
+
+// GenerateKey returns a new random key in the base64 form accepted by
+// [ParseKeyring].
+func GenerateKey() (string, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// Encrypt encrypts plaintext with the primary key and returns it in the
+// format understood by [Init]:
+//
+//	ENC[AES256_GCM,data:<base64>,iv:<base64>,tag:<base64>,kid:<key id>]
+//
+// The kid field is omitted for keys without an ID. Encrypt returns an error if
+// k has no key, e.g. if it is nil.
+//
+// Usage:
+//
+//	keys, _ := config.ParseKeyring(os.Getenv("STANZA_CONFIG_KEY"))
+//	value, _ := keys.Encrypt("secret123")
+//	// database:
+//	//   password: ENC[AES256_GCM,data:...,iv:...,tag:...]
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	if k == nil || len(k.ids) == 0 {
+		return "", errors.New("config: keyring is empty")
+	}
+	id := k.ids[0]
+	gcm, err := newGCM(k.keys[id])
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nil, nonce, []byte(plaintext), nil)
+	data, tag := sealed[:len(sealed)-tagSize], sealed[len(sealed)-tagSize:]
+
+	enc := base64.StdEncoding
+	fields := []string{
+		encAlgorithm,
+		"data:" + enc.EncodeToString(data),
+		"iv:" + enc.EncodeToString(nonce),
+		"tag:" + enc.EncodeToString(tag),
+	}
+	if id != "" {
+		fields = append(fields, "kid:"+id)
+	}
+	return encPrefix + strings.Join(fields, ",") + encSuffix, nil
+}
+
+// Decrypt decrypts a value produced by [Keyring.Encrypt]. Values with a key ID
+// are decrypted with that key; values without one are tried with every key.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if k == nil || len(k.ids) == 0 {
+		return "", errors.New("config: keyring is empty")
+	}
+	token, err := parseEncrypted(value)
+	if err != nil {
+		return "", err
+	}
+
+	ids := k.ids
+	if token.kid != "" {
+		if _, ok := k.keys[token.kid]; !ok {
+			return "", fmt.Errorf("config: unknown key ID %q", token.kid)
+		}
+		ids = []string{token.kid}
+	}
+
+	sealed := append(token.data, token.tag...)
+	for _, id := range ids {
+		gcm, err := newGCM(k.keys[id])
+		if err != nil {
+			return "", err
+		}
+		if plaintext, err := gcm.Open(nil, token.iv, sealed, nil); err == nil {
+			return string(plaintext), nil
+		}
+	}
+	return "", errors.New("config: cannot decrypt value: message authentication failed")
+}
+
+// IsEncrypted reports whether s is an encrypted value (ENC[...]).
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, encPrefix) && strings.HasSuffix(s, encSuffix)
+}
+
+type encryptedValue struct {
+	data, iv, tag []byte
+	kid           string
+}
+
+// parseEncrypted parses an ENC[...] value.
+func parseEncrypted(value string) (encryptedValue, error) {
+	var token encryptedValue
+
+	if !IsEncrypted(value) {
+		return token, errors.New("config: value is not encrypted")
+	}
+	body := strings.TrimSuffix(strings.TrimPrefix(value, encPrefix), encSuffix)
+	fields := strings.Split(body, ",")
+	if fields[0] != encAlgorithm {
+		return token, fmt.Errorf("config: unsupported encryption algorithm %q", fields[0])
+	}
+
+	for _, field := range fields[1:] {
+		name, val, ok := strings.Cut(field, ":")
+		if !ok {
+			return token, fmt.Errorf("config: malformed encrypted value field %q", field)
+		}
+
+		var err error
+		switch name {
+		case "data":
+			token.data, err = base64.StdEncoding.DecodeString(val)
+		case "iv":
+			token.iv, err = base64.StdEncoding.DecodeString(val)
+		case "tag":
+			token.tag, err = base64.StdEncoding.DecodeString(val)
+		case "kid":
+			token.kid = val
+		default:
+			return token, fmt.Errorf("config: unknown encrypted value field %q", name)
+		}
+		if err != nil {
+			return token, fmt.Errorf("config: malformed encrypted value field %q: %w", name, err)
+		}
+	}
+
+	if len(token.iv) != nonceSize || len(token.tag) != tagSize {
+		return token, errors.New("config: malformed encrypted value: invalid iv or tag")
+	}
+	return token, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// decryptValues decrypts every encrypted string in data, the (sub)tree rooted
+// at prefix, into a [Secret], so that it is redacted by [AllSettings].
+// The keyring is loaded from the environment variables read by lookup, only
+// if an encrypted value is found.
+func decryptValues(data map[string]any, prefix string, lookup func(string) (string, bool), keys **Keyring) error {
+	for k, v := range data {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		val, err := decryptValue(v, key, lookup, keys)
+		if err != nil {
+			return err
+		}
+		data[k] = val
+	}
+	return nil
+}
+
+// decryptValue returns v, the value of key, with its encrypted strings
+// decrypted, recursing into maps and lists
+func decryptValue(v any, key string, lookup func(string) (string, bool), keys **Keyring) (any, error) {
+	switch val := v.(type) {
+	case map[string]any:
+		return val, decryptValues(val, key, lookup, keys)
+	case []any:
+		for i, item := range val {
+			item, err := decryptValue(item, key+"."+strconv.Itoa(i), lookup, keys)
+			if err != nil {
+				return nil, err
+			}
+			val[i] = item
+		}
+	case string:
+		if !IsEncrypted(val) {
+			return val, nil
+		}
+		if *keys == nil {
+			keyring, err := loadKeyring(lookup)
+			if err != nil {
+				return nil, err
+			}
+			if keyring == nil {
+				return nil, fmt.Errorf("config: %s is encrypted but neither %s nor %s is set", key, KeyEnv, KeyFileEnv)
+			}
+			*keys = keyring
+		}
+		plaintext, err := (*keys).Decrypt(val)
+		if err != nil {
+			return nil, fmt.Errorf("%w (%s)", err, key)
+		}
+		return Secret(plaintext), nil
+	}
+	return v, nil
+}
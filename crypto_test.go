@@ -0,0 +1,269 @@
+package config
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestKeyring(t *testing.T, ids ...string) (*Keyring, string) {
+	t.Helper()
+
+	var entries []string
+	for _, id := range ids {
+		key, err := GenerateKey()
+		if err != nil {
+			t.Fatalf("GenerateKey returned error: %v", err)
+		}
+		if id != "" {
+			key = id + ":" + key
+		}
+		entries = append(entries, key)
+	}
+	text := strings.Join(entries, ",")
+
+	keys, err := ParseKeyring(text)
+	if err != nil {
+		t.Fatalf("ParseKeyring returned error: %v", err)
+	}
+	return keys, text
+}
+
+func TestKeyring(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		keys, _ := newTestKeyring(t, "")
+
+		enc, err := keys.Encrypt("secret123")
+		if err != nil {
+			t.Fatalf("Encrypt returned error: %v", err)
+		}
+		if !IsEncrypted(enc) || !strings.HasPrefix(enc, "ENC[AES256_GCM,data:") || strings.Contains(enc, "kid:") {
+			t.Errorf("Encrypt() = %q, unexpected format", enc)
+		}
+
+		got, err := keys.Decrypt(enc)
+		if err != nil {
+			t.Fatalf("Decrypt returned error: %v", err)
+		}
+		if got != "secret123" {
+			t.Errorf("Decrypt() = %q, want %q", got, "secret123")
+		}
+	})
+
+	t.Run("key rotation", func(t *testing.T) {
+		oldKeys, oldText := newTestKeyring(t, "2023")
+		enc, err := oldKeys.Encrypt("secret123")
+		if err != nil {
+			t.Fatalf("Encrypt returned error: %v", err)
+		}
+		if !strings.HasSuffix(enc, ",kid:2023]") {
+			t.Errorf("Encrypt() = %q, want kid:2023", enc)
+		}
+
+		_, newText := newTestKeyring(t, "2024")
+		keys, err := ParseKeyring(newText + "\n" + oldText)
+		if err != nil {
+			t.Fatalf("ParseKeyring returned error: %v", err)
+		}
+
+		got, err := keys.Decrypt(enc)
+		if err != nil {
+			t.Fatalf("Decrypt returned error: %v", err)
+		}
+		if got != "secret123" {
+			t.Errorf("Decrypt() = %q, want %q", got, "secret123")
+		}
+
+		reencrypted, _ := keys.Encrypt(got)
+		if !strings.HasSuffix(reencrypted, ",kid:2024]") {
+			t.Errorf("Encrypt() = %q, want primary key kid:2024", reencrypted)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		keys, _ := newTestKeyring(t, "")
+		other, _ := newTestKeyring(t, "")
+
+		enc, _ := keys.Encrypt("secret123")
+		if _, err := other.Decrypt(enc); err == nil {
+			t.Error("Decrypt with wrong key returned nil error")
+		}
+	})
+
+	t.Run("unknown key ID", func(t *testing.T) {
+		keys, _ := newTestKeyring(t, "a")
+		other, _ := newTestKeyring(t, "b")
+
+		enc, _ := keys.Encrypt("secret123")
+		if _, err := other.Decrypt(enc); err == nil || !strings.Contains(err.Error(), `unknown key ID "a"`) {
+			t.Errorf("Decrypt() error = %v, want unknown key ID", err)
+		}
+	})
+
+	t.Run("tampered value", func(t *testing.T) {
+		keys, _ := newTestKeyring(t, "")
+		enc, _ := keys.Encrypt("secret123")
+
+		token, _ := parseEncrypted(enc)
+		token.data[0] ^= 0xff
+		tampered := strings.Replace(enc, enc[strings.Index(enc, "data:"):strings.Index(enc, ",iv:")],
+			"data:"+base64.StdEncoding.EncodeToString(token.data), 1)
+
+		if _, err := keys.Decrypt(tampered); err == nil {
+			t.Error("Decrypt of tampered value returned nil error")
+		}
+	})
+
+	t.Run("empty keyring", func(t *testing.T) {
+		for _, keys := range []*Keyring{nil, {}} {
+			if _, err := keys.Encrypt("secret123"); err == nil {
+				t.Errorf("Encrypt() with keyring %#v error = nil, want error", keys)
+			}
+			if _, err := keys.Decrypt("ENC[AES256_GCM,data:YQ==,iv:YQ==,tag:YQ==]"); err == nil {
+				t.Errorf("Decrypt() with keyring %#v error = nil, want error", keys)
+			}
+		}
+	})
+
+	t.Run("invalid keyrings", func(t *testing.T) {
+		for _, s := range []string{"", "not-base64!", "c2hvcnQ=", "a:" + strings.Repeat("A", 43) + "=,a:" + strings.Repeat("A", 43) + "="} {
+			if _, err := ParseKeyring(s); err == nil {
+				t.Errorf("ParseKeyring(%q) returned nil error", s)
+			}
+		}
+	})
+
+	t.Run("malformed values", func(t *testing.T) {
+		keys, _ := newTestKeyring(t, "")
+		for _, s := range []string{"plain", "ENC[AES128_CBC,data:AA==]", "ENC[AES256_GCM,data]", "ENC[AES256_GCM,data:AA==,iv:AA==,tag:AA==]"} {
+			if _, err := keys.Decrypt(s); err == nil {
+				t.Errorf("Decrypt(%q) returned nil error", s)
+			}
+		}
+	})
+}
+
+func TestLoadKeyring(t *testing.T) {
+	_, text := newTestKeyring(t, "file")
+
+	t.Run("not configured", func(t *testing.T) {
+		keys, err := LoadKeyring()
+		if keys != nil || err != nil {
+			t.Errorf("LoadKeyring() = %v, %v, want nil, nil", keys, err)
+		}
+	})
+
+	t.Run("from file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.key")
+		if err := os.WriteFile(path, []byte(text+"\n"), 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		os.Setenv(KeyFileEnv, path)
+		defer os.Unsetenv(KeyFileEnv)
+
+		keys, err := LoadKeyring()
+		if err != nil || keys == nil {
+			t.Fatalf("LoadKeyring() = %v, %v", keys, err)
+		}
+		if keys.ids[0] != "file" {
+			t.Errorf("primary key ID = %q, want %q", keys.ids[0], "file")
+		}
+	})
+}
+
+func TestInitDecryptsValues(t *testing.T) {
+	keys, text := newTestKeyring(t, "")
+	enc, err := keys.Encrypt("secret123")
+	if err != nil {
+		t.Fatalf("Encrypt returned error: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	content := "database:\n  host: localhost\n  pass: " + enc + "\n" +
+		"replicas:\n  - host: replica\n    pass: " + enc + "\n" +
+		"tokens:\n  - " + enc + "\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "config.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte(KeyEnv+"="+text+"\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Unsetenv(KeyEnv)
+
+	originalDir, _ := os.Getwd()
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	Reset()
+	Init()
+
+	if got := GetString("database.pass"); got != "secret123" {
+		t.Errorf("GetString(database.pass) = %q, want %q", got, "secret123")
+	}
+	replicas, err := GetSlice[struct {
+		Pass string `yaml:"pass"`
+	}]("replicas")
+	if err != nil || len(replicas) != 1 || replicas[0].Pass != "secret123" {
+		t.Errorf("GetSlice(replicas) = %+v, %v, want the password decrypted", replicas, err)
+	}
+	if got := GetStringSlice("tokens"); len(got) != 1 || got[0] != "secret123" {
+		t.Errorf("GetStringSlice(tokens) = %q, want [secret123]", got)
+	}
+	settings := AllSettings()
+	if got := settings["database"].(map[string]any)["pass"]; got != Secret("secret123") {
+		t.Errorf("AllSettings()[database][pass] = %#v, want redacted", got)
+	}
+}
+
+func TestDecryptedValues(t *testing.T) {
+	keys, text := newTestKeyring(t, "")
+	enc, err := keys.Encrypt("5432")
+	if err != nil {
+		t.Fatalf("Encrypt returned error: %v", err)
+	}
+	path := writeTestFile(t, t.TempDir(), "config.yaml", "database:\n  port: "+enc+"\n")
+
+	t.Run("are secret in their Config only", func(t *testing.T) {
+		Reset()
+		cfg, err := New(WithSources(File(path), EnvMap(map[string]string{KeyEnv: text})))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		if got := cfg.GetInt("database.port"); got != 5432 {
+			t.Errorf("GetInt(database.port) = %d, want %d", got, 5432)
+		}
+		if got := cfg.AllSettings()["database"].(map[string]any)["port"]; got != Secret("5432") {
+			t.Errorf("AllSettings()[database][port] = %#v, want redacted", got)
+		}
+		if got := cfg.RevealedSettings()["database"].(map[string]any)["port"]; got != "5432" {
+			t.Errorf("RevealedSettings()[database][port] = %#v, want %q", got, "5432")
+		}
+
+		// The key is not redacted in other Configs
+		Set("database.port", 5432)
+		if got := AllSettings()["database"].(map[string]any)["port"]; got != 5432 {
+			t.Errorf("AllSettings()[database][port] = %#v, want %d", got, 5432)
+		}
+		if IsRedacted("database.port") {
+			t.Error("IsRedacted(database.port) = true, want false")
+		}
+	})
+
+	t.Run("env overrides stay secret", func(t *testing.T) {
+		cfg, err := New(WithSources(File(path), EnvMap(map[string]string{KeyEnv: text, "DATABASE_PORT": "6543"})))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if got := cfg.AllSettings()["database"].(map[string]any)["port"]; got != Secret("6543") {
+			t.Errorf("AllSettings()[database][port] = %#v, want redacted", got)
+		}
+		if got := cfg.GetStringMap("database")["port"]; got != "5432" {
+			t.Errorf("GetStringMap(database)[port] = %#v, want %q", got, "5432")
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// structDefaults returns the `default` tags of the fields of the struct
+// pointed to by v as nested maps, by key relative to the struct. They fill in
+// the keys that are not set when decoding into v, below defaults set via
+// [SetDefault], and never become part of the configuration.
+//
+// Tag values are parsed as YAML scalars, except for string fields (used as-is)
+// and slices, which are comma-separated:
+//
+//	Timeout time.Duration `yaml:"timeout" default:"30s"`
+//	Port    int           `yaml:"port" default:"8080"`
+//	Tags    []string      `yaml:"tags" default:"a,b"`
+func structDefaults(v any) map[string]any {
+	defaults := make(map[string]any)
+	walkStructFields(v, "", func(key string, field reflect.StructField) {
+		if tag, ok := field.Tag.Lookup("default"); ok {
+			setPath(defaults, key, parseDefault(tag, field.Type))
+		}
+	})
+	return defaults
+}
+
+// decodeSettings returns the settings at prefix to decode into a struct with
+// the given `default` tags (see structDefaults): like effectiveSettings, with
+// the tag defaults filling in missing keys before environment variables are
+// applied
+func (s *Snapshot) decodeSettings(prefix string, defaults map[string]any) map[string]any {
+	data := s.treeSettings(prefix)
+	mergeMissing(data, defaults)
+	return s.applyEnvOverrides(data, prefix)
+}
+
+// parseDefault converts a `default` tag value to a value that yaml.v3 can
+// decode into a field of type t.
+func parseDefault(tag string, t reflect.Type) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t.Kind() == reflect.String:
+		return tag
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		parts := splitAndTrimStringSlice(tag)
+		result := make([]any, len(parts))
+		for i, part := range parts {
+			result[i] = part
+			if t.Elem().Kind() != reflect.String {
+				result[i] = parseScalar(part)
+			}
+		}
+		return result
+	default:
+		return parseScalar(tag)
+	}
+}
+
+// parseScalar parses s as a YAML value, returning s itself if it is not valid YAML.
+func parseScalar(s string) any {
+	var v any
+	if err := yaml.Unmarshal([]byte(s), &v); err != nil || v == nil {
+		return s
+	}
+	return v
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSetDefault(t *testing.T) {
+	t.Run("used when key is not set", func(t *testing.T) {
+		Reset()
+		SetDefault("http.port", 8080)
+
+		if got := GetInt("http.port"); got != 8080 {
+			t.Errorf("GetInt(http.port) = %v, want %v", got, 8080)
+		}
+		if !IsSet("http.port") {
+			t.Error("IsSet(http.port) = false, want true")
+		}
+		if got := Origin("http.port"); got != "default" {
+			t.Errorf("Origin(http.port) = %q, want %q", got, "default")
+		}
+	})
+
+	t.Run("config and env take precedence", func(t *testing.T) {
+		Reset()
+		SetDefault("http.port", 8080)
+		Set("http.port", 9000)
+
+		if got := GetInt("http.port"); got != 9000 {
+			t.Errorf("GetInt(http.port) = %v, want %v", got, 9000)
+		}
+		if got := Origin("http.port"); got != "config" {
+			t.Errorf("Origin(http.port) = %q, want %q", got, "config")
+		}
+
+		os.Setenv("HTTP_PORT", "3000")
+		defer os.Unsetenv("HTTP_PORT")
+
+		if got := GetInt("http.port"); got != 3000 {
+			t.Errorf("GetInt(http.port) = %v, want %v", got, 3000)
+		}
+		if got := Origin("http.port"); got != "env" {
+			t.Errorf("Origin(http.port) = %q, want %q", got, "env")
+		}
+	})
+
+	t.Run("Origin of missing key", func(t *testing.T) {
+		Reset()
+		if got := Origin("http.port"); got != "" {
+			t.Errorf("Origin(http.port) = %q, want %q", got, "")
+		}
+	})
+
+	t.Run("AllSettings includes defaults", func(t *testing.T) {
+		Reset()
+		SetDefault("http.port", 8080)
+		SetDefault("http.host", "0.0.0.0")
+		Set("http.host", "localhost")
+
+		want := map[string]any{"http": map[string]any{"port": 8080, "host": "localhost"}}
+		if got := AllSettings(); !reflect.DeepEqual(got, want) {
+			t.Errorf("AllSettings() = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestUnmarshalDefaultTag(t *testing.T) {
+	type HTTPConfig struct {
+		Host    string        `yaml:"host" default:"0.0.0.0"`
+		Port    int           `yaml:"port" default:"8080"`
+		Timeout time.Duration `yaml:"timeout" default:"30s"`
+		Debug   bool          `yaml:"debug" default:"true"`
+		Origins []string      `yaml:"origins" default:"https://a.com, https://b.com"`
+		Ports   []int         `yaml:"ports" default:"80,443"`
+		TLS     struct {
+			Enabled bool   `yaml:"enabled" default:"false"`
+			Version string `yaml:"version" default:"1.3"`
+		} `yaml:"tls"`
+	}
+
+	type Config struct {
+		HTTP HTTPConfig `yaml:"http"`
+	}
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		Reset()
+		Set("http.host", "localhost")
+		os.Setenv("HTTP_PORT", "3000")
+		defer os.Unsetenv("HTTP_PORT")
+
+		var cfg Config
+		if err := Unmarshal(&cfg); err != nil {
+			t.Fatalf("Unmarshal returned error: %v", err)
+		}
+
+		want := HTTPConfig{
+			Host:    "localhost",
+			Port:    3000,
+			Timeout: 30 * time.Second,
+			Debug:   true,
+			Origins: []string{"https://a.com", "https://b.com"},
+			Ports:   []int{80, 443},
+		}
+		want.TLS.Version = "1.3"
+		if !reflect.DeepEqual(cfg.HTTP, want) {
+			t.Errorf("cfg.HTTP = %+v, want %+v", cfg.HTTP, want)
+		}
+
+		// Struct defaults do not change the configuration
+		if got, origin := GetDuration("http.timeout"), Origin("http.timeout"); got != 0 || origin != "" {
+			t.Errorf("GetDuration(http.timeout) = %v from %q, want 0 from no layer", got, origin)
+		}
+	})
+
+	t.Run("Snapshot.Unmarshal", func(t *testing.T) {
+		Reset()
+		Set("http.host", "localhost")
+
+		var cfg Config
+		if err := GetSnapshot().Unmarshal(&cfg); err != nil {
+			t.Fatalf("Unmarshal returned error: %v", err)
+		}
+		if cfg.HTTP.Port != 8080 || cfg.HTTP.Timeout != 30*time.Second || cfg.HTTP.Host != "localhost" {
+			t.Errorf("cfg.HTTP = %+v, want the tag defaults", cfg.HTTP)
+		}
+
+		var http HTTPConfig
+		if err := GetSnapshot().UnmarshalKey("http", &http); err != nil {
+			t.Fatalf("UnmarshalKey returned error: %v", err)
+		}
+		if http.Port != 8080 || http.TLS.Version != "1.3" {
+			t.Errorf("http = %+v, want the tag defaults", http)
+		}
+	})
+
+	t.Run("UnmarshalKey with missing section", func(t *testing.T) {
+		Reset()
+
+		var cfg HTTPConfig
+		if err := UnmarshalKey("http", &cfg); err != nil {
+			t.Fatalf("UnmarshalKey returned error: %v", err)
+		}
+		if cfg.Port != 8080 {
+			t.Errorf("cfg.Port = %v, want %v", cfg.Port, 8080)
+		}
+		if cfg.TLS.Version != "1.3" {
+			t.Errorf("cfg.TLS.Version = %v, want %v", cfg.TLS.Version, "1.3")
+		}
+	})
+
+	t.Run("SetDefault takes precedence over tag", func(t *testing.T) {
+		Reset()
+		SetDefault("http.port", 9090)
+
+		var cfg Config
+		if err := Unmarshal(&cfg); err != nil {
+			t.Fatalf("Unmarshal returned error: %v", err)
+		}
+		if cfg.HTTP.Port != 9090 {
+			t.Errorf("cfg.HTTP.Port = %v, want %v", cfg.HTTP.Port, 9090)
+		}
+	})
+}
+
+func TestParseDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  string
+		typ  reflect.Type
+		want any
+	}{
+		{"string", "true", reflect.TypeOf(""), "true"},
+		{"int", "42", reflect.TypeOf(0), 42},
+		{"bool", "true", reflect.TypeOf(false), true},
+		{"duration", "1m", reflect.TypeOf(time.Duration(0)), "1m"},
+		{"string slice", "a, b", reflect.TypeOf([]string{}), []any{"a", "b"}},
+		{"int slice", "1,2", reflect.TypeOf([]int{}), []any{1, 2}},
+		{"pointer", "7", reflect.TypeOf(new(int)), 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseDefault(tt.tag, tt.typ); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDefault(%q) = %#v, want %#v", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
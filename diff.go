@@ -0,0 +1,127 @@
+package config
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+)
+
+// ChangeKind is the kind of a [Change].
+type ChangeKind string
+
+// Kinds of changes.
+const (
+	Added    ChangeKind = "added"
+	Removed  ChangeKind = "removed"
+	Modified ChangeKind = "modified"
+)
+
+// Change is a difference between two snapshots of the configuration (see
+// [Diff]). Values of secret keys (see [RedactKeys]) are wrapped in [Secret],
+// so they are redacted when printed or marshaled.
+type Change struct {
+	Key  string     `json:"key"`
+	Kind ChangeKind `json:"kind"`
+	Old  any        `json:"old,omitempty"` // nil if Kind is Added
+	New  any        `json:"new,omitempty"` // nil if Kind is Removed
+}
+
+// String returns the change in the format of [WriteDiff].
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s: %v", c.Key, c.New)
+	case Removed:
+		return fmt.Sprintf("- %s: %v", c.Key, c.Old)
+	default:
+		return fmt.Sprintf("~ %s: %v -> %v", c.Key, c.Old, c.New)
+	}
+}
+
+// Diff returns the keys that were added, removed or modified between a and b,
+// sorted by key. Keys are in dot notation and values are the effective ones,
+// with defaults and environment variable overrides applied; slices are
+// compared as a whole.
+//
+// Secret values are compared as usual but redacted in the result.
+//
+// Usage in a reload callback:
+//
+//	before := config.GetSnapshot()
+//	if err := config.Reload(ctx); err == nil {
+//	    for _, change := range config.Diff(before, config.GetSnapshot()) {
+//	        log.Printf("config: %s", change)  // ~ http.port: 8080 -> 9090
+//	    }
+//	}
+func Diff(a, b *Snapshot) []Change {
+	before := make(map[string]any)
+	walkLeaves(a.effectiveSettings(""), "", func(key string, value any) {
+		before[key] = value
+	})
+
+	var changes []Change
+	walkLeaves(b.effectiveSettings(""), "", func(key string, value any) {
+		old, ok := before[key]
+		delete(before, key)
+		switch {
+		case !ok:
+			changes = append(changes, Change{Key: key, Kind: Added, New: redactValue(value, key, false)})
+		case !reflect.DeepEqual(old, value):
+			changes = append(changes, Change{
+				Key:  key,
+				Kind: Modified,
+				Old:  redactValue(old, key, false),
+				New:  redactValue(value, key, false),
+			})
+		}
+	})
+	for key, old := range before {
+		changes = append(changes, Change{Key: key, Kind: Removed, Old: redactValue(old, key, false)})
+	}
+
+	slices.SortFunc(changes, func(x, y Change) int {
+		return cmp.Compare(x.Key, y.Key)
+	})
+	return changes
+}
+
+// WriteDiff writes changes to w, one per line, in a human-readable format:
+//
+//	~ cache.ttl: 1m -> 5m
+//	~ database.password: [REDACTED] -> [REDACTED]
+//	+ feature.beta: true
+//	- legacy.mode: true
+//
+// Usage:
+//
+//	config.WriteDiff(os.Stdout, config.Diff(before, after))
+func WriteDiff(w io.Writer, changes []Change) error {
+	for _, change := range changes {
+		if _, err := fmt.Fprintln(w, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteDiffJSON writes changes to w as an indented JSON array, e.g. for deploy
+// tooling:
+//
+//	[
+//	  {"key": "http.port", "kind": "modified", "old": 8080, "new": 9090}
+//	]
+//
+// Usage:
+//
+//	config.WriteDiffJSON(os.Stdout, config.Diff(before, after))
+func WriteDiffJSON(w io.Writer, changes []Change) error {
+	if changes == nil {
+		changes = []Change{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(changes)
+}
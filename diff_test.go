@@ -0,0 +1,112 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	newSnapshot := func(t *testing.T, values map[string]any) *Snapshot {
+		t.Helper()
+		cfg, err := New(WithSources(Map("test", values)))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		return cfg.Snapshot()
+	}
+
+	a := newSnapshot(t, map[string]any{
+		"http.port":         8080,
+		"http.host":         "localhost",
+		"database.password": "old",
+		"legacy":            true,
+		"tags":              []any{"a"},
+	})
+	b := newSnapshot(t, map[string]any{
+		"http.port":         9090,
+		"http.host":         "localhost",
+		"database.password": "new",
+		"cache.ttl":         "5m",
+		"tags":              []any{"a", "b"},
+	})
+
+	t.Run("lists changes sorted by key", func(t *testing.T) {
+		changes := Diff(a, b)
+
+		want := []struct {
+			key  string
+			kind ChangeKind
+		}{
+			{"cache.ttl", Added},
+			{"database.password", Modified},
+			{"http.port", Modified},
+			{"legacy", Removed},
+			{"tags", Modified},
+		}
+		if len(changes) != len(want) {
+			t.Fatalf("Diff() = %v, want %d changes", changes, len(want))
+		}
+		for i, w := range want {
+			if changes[i].Key != w.key || changes[i].Kind != w.kind {
+				t.Errorf("Diff()[%d] = %s %s, want %s %s", i, changes[i].Kind, changes[i].Key, w.kind, w.key)
+			}
+		}
+		if changes[2].Old != 8080 || changes[2].New != 9090 {
+			t.Errorf("Diff()[2] = %v, want 8080 -> 9090", changes[2])
+		}
+	})
+
+	t.Run("identical snapshots", func(t *testing.T) {
+		if changes := Diff(a, a); len(changes) != 0 {
+			t.Errorf("Diff(a, a) = %v, want none", changes)
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteDiff(&buf, Diff(a, b)); err != nil {
+			t.Fatalf("WriteDiff() error = %v", err)
+		}
+		want := "+ cache.ttl: 5m\n" +
+			"~ database.password: [REDACTED] -> [REDACTED]\n" +
+			"~ http.port: 8080 -> 9090\n" +
+			"- legacy: true\n" +
+			"~ tags: [a] -> [a b]\n"
+		if got := buf.String(); got != want {
+			t.Errorf("WriteDiff() =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteDiffJSON(&buf, Diff(a, b)[1:3]); err != nil {
+			t.Fatalf("WriteDiffJSON() error = %v", err)
+		}
+		want := `[
+  {
+    "key": "database.password",
+    "kind": "modified",
+    "old": "[REDACTED]",
+    "new": "[REDACTED]"
+  },
+  {
+    "key": "http.port",
+    "kind": "modified",
+    "old": 8080,
+    "new": 9090
+  }
+]
+`
+		if got := buf.String(); got != want {
+			t.Errorf("WriteDiffJSON() =\n%s\nwant\n%s", got, want)
+		}
+
+		buf.Reset()
+		if err := WriteDiffJSON(&buf, nil); err != nil {
+			t.Fatalf("WriteDiffJSON() error = %v", err)
+		}
+		if got := buf.String(); got != "[]\n" {
+			t.Errorf("WriteDiffJSON(nil) = %q, want %q", got, "[]\n")
+		}
+	})
+}
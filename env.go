@@ -8,6 +8,13 @@ import (
 
 // loadDotenv parses a .env file and sets environment variables
 func loadDotenv(path string) error {
+	return parseDotenv(path, func(key, value string) {
+		os.Setenv(key, value)
+	})
+}
+
+// parseDotenv parses a .env file and calls fn for every KEY=VALUE pair
+func parseDotenv(path string, fn func(key, value string)) error {
 	file, err := os.Open(path)
 	if err != nil {
 		return err
@@ -40,15 +47,30 @@ func loadDotenv(path string) error {
 			}
 		}
 
-		os.Setenv(key, value)
+		fn(key, value)
 	}
 
 	return scanner.Err()
 }
 
-// getEnvValue checks for an environment variable override
+// EnvName returns the name of the environment variable that overrides key,
+// e.g. for documentation or deployment manifests.
+//
+// Usage:
+//
+//	config.EnvName("database.host")  // DATABASE_HOST
+func EnvName(key string) string {
+	return envName(key)
+}
+
+// envName converts a dot notation key to its environment variable name
+// Converts "db.host" -> "DB_HOST"
+func envName(key string) string {
+	return lookupKey(key).env
+}
+
+// getEnvValue checks for an environment variable override in the default Config
 // Converts "db.host" -> "DB_HOST"
 func getEnvValue(key string) (string, bool) {
-	envKey := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
-	return os.LookupEnv(envKey)
+	return std.Snapshot().getEnvValue(key)
 }
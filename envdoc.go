@@ -0,0 +1,283 @@
+package config
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// EnvVar documents the environment variable that overrides a configuration
+// key (see [EnvVars]).
+type EnvVar struct {
+	Key         string   // dot notation key, e.g. http.port
+	Name        string   // environment variable name, e.g. HTTP_PORT
+	Type        string   // string, bool, int, uint, float, duration, []string or []int
+	Default     string   // value used when the variable is not set; empty for secrets
+	Description string   // from the `desc` struct tag, if any
+	Secret      bool     // whether the value is redacted (see [RedactKeys])
+	Collisions  []string // other keys overridden by the same variable
+}
+
+// EnvVars returns the environment variables that override the known
+// configuration keys, sorted by name. Known keys are the keys of the loaded
+// configuration (including defaults) and the fields of the optional structs,
+// which use the same `yaml` tags as [Unmarshal].
+//
+// Struct fields may document their key with a `desc` tag; their `default`
+// tag is used when the key has no value in the configuration. Keys whose
+// names map to the same variable (e.g., http.port and http_port) are listed
+// in Collisions, since they cannot be set independently.
+//
+// Config file example (config.yaml):
+//
+//	http:
+//	  port: 8080
+//
+// Usage:
+//
+//	type Config struct {
+//	    HTTP struct {
+//	        Port    int           `yaml:"port" desc:"Port to listen on"`
+//	        Timeout time.Duration `yaml:"timeout" default:"30s"`
+//	    } `yaml:"http"`
+//	}
+//
+//	vars := config.EnvVars(&Config{})
+//	// [{http.port HTTP_PORT int 8080 Port to listen on false []}
+//	//  {http.timeout HTTP_TIMEOUT duration 30s  false []}]
+//	config.WriteEnvExample(os.Stdout, vars)
+func EnvVars(structs ...any) []EnvVar {
+	return std.EnvVars(structs...)
+}
+
+// EnvVars is like [EnvVars] but reads from c.
+func (c *Config) EnvVars(structs ...any) []EnvVar {
+	return c.Snapshot().EnvVars(structs...)
+}
+
+// EnvVars is like [EnvVars] but reads from s.
+func (s *Snapshot) EnvVars(structs ...any) []EnvVar {
+	vars := make(map[string]*EnvVar)
+
+	walkLeaves(s.treeSettings(""), "", func(key string, value any) {
+		_, secret := value.(Secret)
+		vars[key] = &EnvVar{
+			Key:     key,
+			Type:    flagKindOf(value).String(),
+			Default: EnvValue(value),
+			Secret:  secret,
+		}
+	})
+	for _, v := range structs {
+		walkStructFields(v, "", func(key string, field reflect.StructField) {
+			kind, ok := flagKindOfType(field.Type)
+			if !ok {
+				return
+			}
+			ev, ok := vars[key]
+			if !ok {
+				ev = &EnvVar{Key: key, Type: kind.String()}
+				if tag, ok := field.Tag.Lookup("default"); ok {
+					ev.Default = EnvValue(parseDefault(tag, field.Type))
+				}
+				vars[key] = ev
+			}
+			ev.Description = field.Tag.Get("desc")
+			t := field.Type
+			for t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			ev.Secret = ev.Secret || field.Tag.Get("secret") == "true" || t == reflect.TypeFor[Secret]()
+		})
+	}
+
+	byName := make(map[string][]string)
+	for key, ev := range vars {
+		ev.Name = envName(key)
+		ev.Secret = ev.Secret || isSecretKey(key)
+		if ev.Secret {
+			ev.Default = ""
+		}
+		byName[ev.Name] = append(byName[ev.Name], key)
+	}
+
+	result := make([]EnvVar, 0, len(vars))
+	for key, ev := range vars {
+		for _, other := range byName[ev.Name] {
+			if other != key {
+				ev.Collisions = append(ev.Collisions, other)
+			}
+		}
+		slices.Sort(ev.Collisions)
+		result = append(result, *ev)
+	}
+	slices.SortFunc(result, func(a, b EnvVar) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Key, b.Key))
+	})
+	return result
+}
+
+// WriteEnvExample writes vars to w in the format of a .env file, e.g. to
+// generate .env.example. Every variable is preceded by a comment with its key,
+// type and description; secrets are left empty.
+//
+//	# http.port (int): Port to listen on
+//	HTTP_PORT=8080
+//
+// Usage:
+//
+//	f, _ := os.Create(".env.example")
+//	defer f.Close()
+//	config.WriteEnvExample(f, config.EnvVars(&Config{}))
+func WriteEnvExample(w io.Writer, vars []EnvVar) error {
+	for i, ev := range vars {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+
+		comment := fmt.Sprintf("# %s (%s)", ev.Key, ev.Type)
+		if ev.Secret {
+			comment = fmt.Sprintf("# %s (%s, secret)", ev.Key, ev.Type)
+		}
+		if ev.Description != "" {
+			comment += ": " + ev.Description
+		}
+		lines := []string{comment}
+		if len(ev.Collisions) > 0 {
+			lines = append(lines, "# WARNING: also overrides "+strings.Join(ev.Collisions, ", "))
+		}
+		lines = append(lines, ev.Name+"="+QuoteEnvValue(ev.Default))
+
+		for _, line := range lines {
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteEnvMarkdown writes vars to w as a Markdown table, e.g. for a README.
+// Colliding variables are flagged in the description.
+//
+//	| Variable | Key | Type | Default | Description |
+//	|----------|-----|------|---------|-------------|
+//	| `HTTP_PORT` | `http.port` | int | `8080` | Port to listen on |
+//
+// Usage:
+//
+//	config.WriteEnvMarkdown(os.Stdout, config.EnvVars(&Config{}))
+func WriteEnvMarkdown(w io.Writer, vars []EnvVar) error {
+	lines := []string{
+		"| Variable | Key | Type | Default | Description |",
+		"|----------|-----|------|---------|-------------|",
+	}
+	for _, ev := range vars {
+		def := ""
+		if ev.Secret {
+			def = "*secret*"
+		} else if ev.Default != "" {
+			def = "`" + ev.Default + "`"
+		}
+		desc := ev.Description
+		if len(ev.Collisions) > 0 {
+			note := "**Collides with** `" + strings.Join(ev.Collisions, "`, `") + "`"
+			desc = strings.TrimSpace(desc + " " + note)
+		}
+		lines = append(lines, fmt.Sprintf("| `%s` | `%s` | %s | %s | %s |",
+			ev.Name, ev.Key, ev.Type, escapeMarkdownCell(def), escapeMarkdownCell(desc)))
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String returns the name of the type of value k accepts
+func (k flagKind) String() string {
+	switch k {
+	case boolFlag:
+		return "bool"
+	case intFlag:
+		return "int"
+	case uintFlag:
+		return "uint"
+	case floatFlag:
+		return "float"
+	case durationFlag:
+		return "duration"
+	case stringSliceFlag:
+		return "[]string"
+	case intSliceFlag:
+		return "[]int"
+	default:
+		return "string"
+	}
+}
+
+// EnvValue formats v the way it is written in an environment variable, e.g.
+// to print the effective configuration as a .env file: lists are
+// comma-separated and secrets (see [Secret]) are redacted.
+//
+// Usage:
+//
+//	config.EnvValue([]any{"a", "b"})  // a,b
+//	config.EnvValue(30 * time.Second) // 30s
+func EnvValue(v any) string {
+	switch val := v.(type) {
+	case []any:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = EnvValue(item)
+		}
+		return strings.Join(items, ",")
+	case []string:
+		return strings.Join(val, ",")
+	case []int:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = strconv.Itoa(item)
+		}
+		return strings.Join(items, ",")
+	case Secret:
+		return val.String()
+	case string, []byte, float64:
+		return toString(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// QuoteEnvValue quotes s, a value formatted with [EnvValue], if a .env file
+// parser would not read it back unchanged (e.g., because of a # or of leading
+// spaces).
+//
+// Usage:
+//
+//	fmt.Printf("%s=%s\n", config.EnvName(key), config.QuoteEnvValue(config.EnvValue(value)))
+func QuoteEnvValue(s string) string {
+	if s != strings.TrimSpace(s) || strings.ContainsAny(s, "#\"'") {
+		if strings.Contains(s, `"`) {
+			return "'" + s + "'"
+		}
+		return `"` + s + `"`
+	}
+	return s
+}
+
+// escapeMarkdownCell escapes the characters of s that would break a table cell
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEnvVars(t *testing.T) {
+	type AppConfig struct {
+		HTTP struct {
+			Port    int           `yaml:"port" desc:"Port to listen on"`
+			Timeout time.Duration `yaml:"timeout" default:"30s"`
+			Hosts   []string      `yaml:"hosts" default:"a,b"`
+		} `yaml:"http"`
+		Database struct {
+			Password Secret `yaml:"password"`
+		} `yaml:"database"`
+	}
+
+	t.Run("config tree and structs", func(t *testing.T) {
+		Reset()
+		Set("http.port", 8080)
+		Set("http_port", 9090)
+		Set("database.password", "hunter2")
+
+		got := EnvVars(&AppConfig{})
+		want := []EnvVar{
+			{Key: "database.password", Name: "DATABASE_PASSWORD", Type: "string", Secret: true},
+			{Key: "http.hosts", Name: "HTTP_HOSTS", Type: "[]string", Default: "a,b"},
+			{Key: "http.port", Name: "HTTP_PORT", Type: "int", Default: "8080", Description: "Port to listen on", Collisions: []string{"http_port"}},
+			{Key: "http_port", Name: "HTTP_PORT", Type: "int", Default: "9090", Collisions: []string{"http.port"}},
+			{Key: "http.timeout", Name: "HTTP_TIMEOUT", Type: "duration", Default: "30s"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("EnvVars() =\n%+v\nwant\n%+v", got, want)
+		}
+	})
+
+	t.Run("environment variables are not defaults", func(t *testing.T) {
+		Reset()
+		Set("http.port", 8080)
+		os.Setenv("HTTP_PORT", "9000")
+		defer os.Unsetenv("HTTP_PORT")
+
+		got := EnvVars()
+		if len(got) != 1 || got[0].Default != "8080" {
+			t.Errorf("EnvVars() = %+v, want default 8080", got)
+		}
+	})
+}
+
+func TestWriteEnvDocs(t *testing.T) {
+	vars := []EnvVar{
+		{Key: "database.password", Name: "DATABASE_PASSWORD", Type: "string", Secret: true},
+		{Key: "http.port", Name: "HTTP_PORT", Type: "int", Default: "8080", Description: "Port to listen on", Collisions: []string{"http_port"}},
+		{Key: "app.motd", Name: "APP_MOTD", Type: "string", Default: "hello # world", Description: "a | b"},
+	}
+
+	t.Run("env example", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteEnvExample(&buf, vars); err != nil {
+			t.Fatalf("WriteEnvExample() error = %v", err)
+		}
+		want := "# database.password (string, secret)\n" +
+			"DATABASE_PASSWORD=\n" +
+			"\n" +
+			"# http.port (int): Port to listen on\n" +
+			"# WARNING: also overrides http_port\n" +
+			"HTTP_PORT=8080\n" +
+			"\n" +
+			"# app.motd (string): a | b\n" +
+			"APP_MOTD=\"hello # world\"\n"
+		if got := buf.String(); got != want {
+			t.Errorf("WriteEnvExample() =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteEnvMarkdown(&buf, vars); err != nil {
+			t.Fatalf("WriteEnvMarkdown() error = %v", err)
+		}
+		want := "| Variable | Key | Type | Default | Description |\n" +
+			"|----------|-----|------|---------|-------------|\n" +
+			"| `DATABASE_PASSWORD` | `database.password` | string | *secret* |  |\n" +
+			"| `HTTP_PORT` | `http.port` | int | `8080` | Port to listen on **Collides with** `http_port` |\n" +
+			"| `APP_MOTD` | `app.motd` | string | `hello # world` | a \\| b |\n"
+		if got := buf.String(); got != want {
+			t.Errorf("WriteEnvMarkdown() =\n%s\nwant\n%s", got, want)
+		}
+	})
+}
+
+func TestEnvValue(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{nil, ""},
+		{"localhost", "localhost"},
+		{8080, "8080"},
+		{1.5, "1.5"},
+		{true, "true"},
+		{30 * time.Second, "30s"},
+		{[]any{"a", 1, []any{"b", "c"}}, "a,1,b,c"},
+		{[]string{"a", "b"}, "a,b"},
+		{[]int{1, 2}, "1,2"},
+		{Secret("hunter2"), "[REDACTED]"},
+	}
+	for _, tt := range tests {
+		if got := EnvValue(tt.value); got != tt.want {
+			t.Errorf("EnvValue(%#v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+
+	for value, want := range map[string]string{
+		"plain":         "plain",
+		"":              "",
+		"hello # world": `"hello # world"`,
+		" padded":       `" padded"`,
+	} {
+		if got := QuoteEnvValue(value); got != want {
+			t.Errorf("QuoteEnvValue(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// flagsLayer is the name of the layer holding explicitly set flags.
+const flagsLayer = "flags"
+
+// BindFlags registers a flag on fs for every known configuration key, named
+// after the key in dot notation (e.g., --http.port).
+//
+// Known keys are the keys of the loaded configuration (including defaults)
+// and the fields of the optional structs, which use the same `yaml` tags as
+// [Unmarshal]. Flags already defined on fs are left untouched.
+//
+// Flags that are explicitly set on the command line rank above environment
+// variables; flags that are not set do not affect the configuration.
+//
+// Usage:
+//
+//	config.Init()
+//	config.BindFlags(flag.CommandLine)
+//	flag.Parse()
+//
+//	port := config.GetInt("http.port")  // --http.port=9000 wins over HTTP_PORT and config.yaml
+func BindFlags(fs *flag.FlagSet, structs ...any) {
+	std.BindFlags(fs, structs...)
+}
+
+// BindFlags is like [BindFlags] but binds the flags to c.
+func (c *Config) BindFlags(fs *flag.FlagSet, structs ...any) {
+	kinds := make(map[string]flagKind)
+
+	walkLeaves(c.Snapshot().effectiveSettings(""), "", func(key string, value any) {
+		kinds[key] = flagKindOf(value)
+	})
+	for _, v := range structs {
+		walkStructFields(v, "", func(key string, field reflect.StructField) {
+			if _, ok := kinds[key]; ok {
+				return
+			}
+			if kind, ok := flagKindOfType(field.Type); ok {
+				kinds[key] = kind
+			}
+		})
+	}
+
+	c.update(func(next *Snapshot) {
+		if !slices.ContainsFunc(next.layers, func(l *layer) bool { return l.name == flagsLayer }) {
+			next.layers = append(next.layers, &layer{name: flagsLayer, kind: treeLayer, data: make(map[string]any)})
+		}
+	})
+
+	for _, key := range slices.Sorted(maps.Keys(kinds)) {
+		if fs.Lookup(key) != nil {
+			continue
+		}
+		usage := fmt.Sprintf("config key %s (env %s)", key, envName(key))
+		fs.Var(&flagValue{c: c, key: key, kind: kinds[key]}, key, usage)
+	}
+}
+
+// flagKind is the type of value a flag accepts.
+type flagKind int
+
+const (
+	stringFlag flagKind = iota
+	boolFlag
+	intFlag
+	uintFlag
+	floatFlag
+	durationFlag
+	stringSliceFlag
+	intSliceFlag
+)
+
+// flagKindOf returns the flag kind matching a configuration value.
+func flagKindOf(value any) flagKind {
+	switch val := value.(type) {
+	case bool:
+		return boolFlag
+	case int, int32, int64:
+		return intFlag
+	case uint, uint16, uint32, uint64:
+		return uintFlag
+	case float32, float64:
+		return floatFlag
+	case time.Duration:
+		return durationFlag
+	case []string:
+		return stringSliceFlag
+	case []int:
+		return intSliceFlag
+	case []any:
+		if len(val) > 0 {
+			switch val[0].(type) {
+			case int, int64:
+				return intSliceFlag
+			}
+		}
+		return stringSliceFlag
+	default:
+		return stringFlag
+	}
+}
+
+// flagKindOfType returns the flag kind matching a struct field type, or false
+// for types that cannot be set from a flag (e.g., nested structs).
+func flagKindOfType(t reflect.Type) (flagKind, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeFor[time.Duration]() {
+		return durationFlag, true
+	}
+	if reflect.PointerTo(t).Implements(reflect.TypeFor[encoding.TextUnmarshaler]()) {
+		return stringFlag, true // e.g. ByteSize, parsed by the getters
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return stringFlag, true
+	case reflect.Bool:
+		return boolFlag, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return intFlag, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return uintFlag, true
+	case reflect.Float32, reflect.Float64:
+		return floatFlag, true
+	case reflect.Slice:
+		switch t.Elem().Kind() {
+		case reflect.String:
+			return stringSliceFlag, true
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return intSliceFlag, true
+		}
+	}
+	return 0, false
+}
+
+// flagValue is a [flag.Value] that stores the flag in the flags layer of a Config.
+type flagValue struct {
+	c    *Config
+	key  string
+	kind flagKind
+}
+
+// String returns the current value of the key, redacted for secret keys.
+func (f *flagValue) String() string {
+	if f == nil || f.c == nil {
+		return ""
+	}
+	if isSecretKey(f.key) {
+		return redacted
+	}
+
+	switch f.kind {
+	case stringSliceFlag:
+		return strings.Join(f.c.GetStringSlice(f.key), ",")
+	case intSliceFlag:
+		ints := f.c.GetIntSlice(f.key)
+		parts := make([]string, len(ints))
+		for i, n := range ints {
+			parts[i] = strconv.Itoa(n)
+		}
+		return strings.Join(parts, ",")
+	default:
+		return f.c.GetString(f.key)
+	}
+}
+
+// Set parses s and stores it in the flags layer.
+func (f *flagValue) Set(s string) error {
+	var value any
+
+	switch f.kind {
+	case boolFlag:
+		if _, err := strconv.ParseBool(s); err != nil {
+			return err
+		}
+		value = toBool(s)
+	case intFlag:
+		if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+			return err
+		}
+		value = toInt64(s)
+	case uintFlag:
+		u, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		if u <= math.MaxInt64 {
+			value = int64(u)
+		} else {
+			value = u
+		}
+	case floatFlag:
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return err
+		}
+		value = toFloat64(s)
+	case durationFlag:
+		if _, err := ParseDuration(s); err != nil {
+			return err
+		}
+		value = s
+	case stringSliceFlag:
+		value = splitAndTrimStringSlice(s)
+	case intSliceFlag:
+		for _, part := range splitAndTrimStringSlice(s) {
+			if _, err := strconv.Atoi(part); err != nil {
+				return err
+			}
+		}
+		value = splitAndTrimIntSlice(s)
+	default:
+		value = s
+	}
+
+	f.c.update(func(next *Snapshot) {
+		for _, l := range next.layers {
+			if l.name == flagsLayer {
+				l.set(f.key, value)
+			}
+		}
+	})
+	return nil
+}
+
+// IsBoolFlag allows boolean flags to be set without a value (e.g., --app.debug).
+func (f *flagValue) IsBoolFlag() bool {
+	return f.kind == boolFlag
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+func TestBindFlags(t *testing.T) {
+	t.Run("explicit flag takes precedence over env and config", func(t *testing.T) {
+		Reset()
+		Set("http.port", 8080)
+		os.Setenv("HTTP_PORT", "3000")
+		defer os.Unsetenv("HTTP_PORT")
+
+		fs := newFlagSet()
+		BindFlags(fs)
+		if err := fs.Parse([]string{"--http.port=9000"}); err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+
+		if got := GetInt("http.port"); got != 9000 {
+			t.Errorf("GetInt(http.port) = %v, want %v", got, 9000)
+		}
+		if got := Origin("http.port"); got != "flags" {
+			t.Errorf("Origin(http.port) = %q, want %q", got, "flags")
+		}
+	})
+
+	t.Run("unset flag does not affect config", func(t *testing.T) {
+		Reset()
+		Set("http.port", 8080)
+		Set("http.host", "localhost")
+		os.Setenv("HTTP_PORT", "3000")
+		defer os.Unsetenv("HTTP_PORT")
+
+		fs := newFlagSet()
+		BindFlags(fs)
+		if err := fs.Parse(nil); err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+
+		if got := GetInt("http.port"); got != 3000 {
+			t.Errorf("GetInt(http.port) = %v, want %v", got, 3000)
+		}
+		if got := GetString("http.host"); got != "localhost" {
+			t.Errorf("GetString(http.host) = %q, want %q", got, "localhost")
+		}
+	})
+
+	t.Run("flags from struct fields", func(t *testing.T) {
+		Reset()
+
+		type Config struct {
+			HTTP struct {
+				Port    int           `yaml:"port"`
+				Timeout time.Duration `yaml:"timeout"`
+			} `yaml:"http"`
+			Tags []string `yaml:"tags"`
+		}
+
+		fs := newFlagSet()
+		BindFlags(fs, &Config{})
+		err := fs.Parse([]string{"--http.port", "9000", "--http.timeout", "1d", "--tags", "a, b"})
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+
+		var cfg Config
+		if err := Unmarshal(&cfg); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		if cfg.HTTP.Port != 9000 {
+			t.Errorf("HTTP.Port = %v, want %v", cfg.HTTP.Port, 9000)
+		}
+		if cfg.HTTP.Timeout != 24*time.Hour {
+			t.Errorf("HTTP.Timeout = %v, want %v", cfg.HTTP.Timeout, 24*time.Hour)
+		}
+		if want := []string{"a", "b"}; !reflect.DeepEqual(cfg.Tags, want) {
+			t.Errorf("Tags = %v, want %v", cfg.Tags, want)
+		}
+	})
+
+	t.Run("bool flag without value", func(t *testing.T) {
+		Reset()
+		Set("app.debug", false)
+
+		fs := newFlagSet()
+		BindFlags(fs)
+		if err := fs.Parse([]string{"--app.debug"}); err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+
+		if !GetBool("app.debug") {
+			t.Error("GetBool(app.debug) = false, want true")
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		Reset()
+		Set("http.port", 8080)
+
+		fs := newFlagSet()
+		BindFlags(fs)
+		if err := fs.Parse([]string{"--http.port=abc"}); err == nil {
+			t.Error("Parse() error = nil, want error")
+		}
+		if got := GetInt("http.port"); got != 8080 {
+			t.Errorf("GetInt(http.port) = %v, want %v", got, 8080)
+		}
+	})
+
+	t.Run("existing flags are kept", func(t *testing.T) {
+		Reset()
+		Set("http.port", 8080)
+
+		fs := newFlagSet()
+		port := fs.Int("http.port", 1, "port")
+		BindFlags(fs)
+		if err := fs.Parse([]string{"--http.port=9000"}); err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+
+		if *port != 9000 {
+			t.Errorf("port = %v, want %v", *port, 9000)
+		}
+		if got := GetInt("http.port"); got != 8080 {
+			t.Errorf("GetInt(http.port) = %v, want %v", got, 8080)
+		}
+	})
+
+	t.Run("secret defaults are redacted", func(t *testing.T) {
+		Reset()
+		Set("database.password", "secret123")
+
+		fs := newFlagSet()
+		BindFlags(fs)
+
+		f := fs.Lookup("database.password")
+		if f == nil {
+			t.Fatal("Lookup(database.password) = nil")
+		}
+		if strings.Contains(f.DefValue, "secret123") {
+			t.Errorf("DefValue = %q, want redacted", f.DefValue)
+		}
+		if !strings.Contains(f.Usage, "DATABASE_PASSWORD") {
+			t.Errorf("Usage = %q, want env name", f.Usage)
+		}
+	})
+}
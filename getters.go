@@ -1,6 +1,9 @@
 package config
 
 import (
+	"maps"
+	"reflect"
+	"strconv"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -11,6 +14,7 @@ import (
 // A key is considered "set" if it exists in either:
 //  1. Environment variables (key converted to UPPER_SNAKE_CASE)
 //  2. The config file (config.yaml)
+//  3. Defaults (see [SetDefault])
 //
 // Config file example (config.yaml):
 //
@@ -23,13 +27,59 @@ import (
 //	    name := config.GetString("app.name")
 //	}
 func IsSet(key string) bool {
-	if _, ok := getEnvValue(key); ok {
+	return std.IsSet(key)
+}
+
+// IsSet is like [IsSet] but reads from c.
+func (c *Config) IsSet(key string) bool {
+	return c.Snapshot().IsSet(key)
+}
+
+// IsSet is like [IsSet] but reads from s.
+func (s *Snapshot) IsSet(key string) bool {
+	if _, ok := s.getEnvValue(key); ok {
 		return true
 	}
-	_, ok := getFromMap(key)
+	_, ok := s.getFromMap(key)
 	return ok
 }
 
+// Origin reports which layer supplies the value of the given key.
+//
+// Returns one of:
+//   - "env": an environment variable (key converted to UPPER_SNAKE_CASE)
+//   - "config": the config file, or a value set via [Set]
+//   - "default": a default set via [SetDefault]
+//   - "": the key is not set
+//
+// For a [Config] created with [New], the layers are named after their sources
+// (see [Source]).
+//
+// Keys resolved through a deprecated alias (see [Alias]) report the layer of
+// the alias.
+//
+// Usage:
+//
+//	config.SetDefault("http.port", 8080)
+//	config.Origin("http.port")  // returns "default", or "env" if HTTP_PORT is set
+func Origin(key string) string {
+	return std.Origin(key)
+}
+
+// Origin is like [Origin] but reads from c.
+func (c *Config) Origin(key string) string {
+	return c.Snapshot().Origin(key)
+}
+
+// Origin is like [Origin] but reads from s.
+func (s *Snapshot) Origin(key string) string {
+	_, l, ok := s.find(key, false)
+	if !ok {
+		return ""
+	}
+	return l.name
+}
+
 // GetString returns the string value associated with the given key.
 //
 // Lookup order:
@@ -52,10 +102,20 @@ func IsSet(key string) bool {
 //
 //	name := config.GetString("app.name")  // returns "myapp", or "otherapp" if env var is set
 func GetString(key string) string {
-	if val, ok := getEnvValue(key); ok {
+	return std.GetString(key)
+}
+
+// GetString is like [GetString] but reads from c.
+func (c *Config) GetString(key string) string {
+	return c.Snapshot().GetString(key)
+}
+
+// GetString is like [GetString] but reads from s.
+func (s *Snapshot) GetString(key string) string {
+	if val, ok := s.getEnvValue(key); ok {
 		return val
 	}
-	if val, ok := getFromMap(key); ok {
+	if val, ok := s.getFromMap(key); ok {
 		return toString(val)
 	}
 	return ""
@@ -87,10 +147,20 @@ func GetString(key string) string {
 //	    log.SetLevel(log.DebugLevel)
 //	}
 func GetBool(key string) bool {
-	if val, ok := getEnvValue(key); ok {
+	return std.GetBool(key)
+}
+
+// GetBool is like [GetBool] but reads from c.
+func (c *Config) GetBool(key string) bool {
+	return c.Snapshot().GetBool(key)
+}
+
+// GetBool is like [GetBool] but reads from s.
+func (s *Snapshot) GetBool(key string) bool {
+	if val, ok := s.getEnvValue(key); ok {
 		return toBool(val)
 	}
-	if val, ok := getFromMap(key); ok {
+	if val, ok := s.getFromMap(key); ok {
 		return toBool(val)
 	}
 	return false
@@ -117,10 +187,20 @@ func GetBool(key string) bool {
 //
 //	port := config.GetInt("http.port")  // returns 8080, or 3000 if env var is set
 func GetInt(key string) int {
-	if val, ok := getEnvValue(key); ok {
+	return std.GetInt(key)
+}
+
+// GetInt is like [GetInt] but reads from c.
+func (c *Config) GetInt(key string) int {
+	return c.Snapshot().GetInt(key)
+}
+
+// GetInt is like [GetInt] but reads from s.
+func (s *Snapshot) GetInt(key string) int {
+	if val, ok := s.getEnvValue(key); ok {
 		return toInt(val)
 	}
-	if val, ok := getFromMap(key); ok {
+	if val, ok := s.getFromMap(key); ok {
 		return toInt(val)
 	}
 	return 0
@@ -143,10 +223,20 @@ func GetInt(key string) int {
 //
 //	maxConn := config.GetInt32("limits.max_connections")
 func GetInt32(key string) int32 {
-	if val, ok := getEnvValue(key); ok {
+	return std.GetInt32(key)
+}
+
+// GetInt32 is like [GetInt32] but reads from c.
+func (c *Config) GetInt32(key string) int32 {
+	return c.Snapshot().GetInt32(key)
+}
+
+// GetInt32 is like [GetInt32] but reads from s.
+func (s *Snapshot) GetInt32(key string) int32 {
+	if val, ok := s.getEnvValue(key); ok {
 		return toInt32(val)
 	}
-	if val, ok := getFromMap(key); ok {
+	if val, ok := s.getFromMap(key); ok {
 		return toInt32(val)
 	}
 	return 0
@@ -169,10 +259,20 @@ func GetInt32(key string) int32 {
 //
 //	maxSize := config.GetInt64("storage.max_file_size")
 func GetInt64(key string) int64 {
-	if val, ok := getEnvValue(key); ok {
+	return std.GetInt64(key)
+}
+
+// GetInt64 is like [GetInt64] but reads from c.
+func (c *Config) GetInt64(key string) int64 {
+	return c.Snapshot().GetInt64(key)
+}
+
+// GetInt64 is like [GetInt64] but reads from s.
+func (s *Snapshot) GetInt64(key string) int64 {
+	if val, ok := s.getEnvValue(key); ok {
 		return toInt64(val)
 	}
-	if val, ok := getFromMap(key); ok {
+	if val, ok := s.getFromMap(key); ok {
 		return toInt64(val)
 	}
 	return 0
@@ -195,10 +295,20 @@ func GetInt64(key string) int64 {
 //
 //	poolSize := config.GetUint("worker.pool_size")
 func GetUint(key string) uint {
-	if val, ok := getEnvValue(key); ok {
+	return std.GetUint(key)
+}
+
+// GetUint is like [GetUint] but reads from c.
+func (c *Config) GetUint(key string) uint {
+	return c.Snapshot().GetUint(key)
+}
+
+// GetUint is like [GetUint] but reads from s.
+func (s *Snapshot) GetUint(key string) uint {
+	if val, ok := s.getEnvValue(key); ok {
 		return toUint(val)
 	}
-	if val, ok := getFromMap(key); ok {
+	if val, ok := s.getFromMap(key); ok {
 		return toUint(val)
 	}
 	return 0
@@ -221,10 +331,20 @@ func GetUint(key string) uint {
 //
 //	port := config.GetUint16("http.port")
 func GetUint16(key string) uint16 {
-	if val, ok := getEnvValue(key); ok {
+	return std.GetUint16(key)
+}
+
+// GetUint16 is like [GetUint16] but reads from c.
+func (c *Config) GetUint16(key string) uint16 {
+	return c.Snapshot().GetUint16(key)
+}
+
+// GetUint16 is like [GetUint16] but reads from s.
+func (s *Snapshot) GetUint16(key string) uint16 {
+	if val, ok := s.getEnvValue(key); ok {
 		return toUint16(val)
 	}
-	if val, ok := getFromMap(key); ok {
+	if val, ok := s.getFromMap(key); ok {
 		return toUint16(val)
 	}
 	return 0
@@ -247,10 +367,20 @@ func GetUint16(key string) uint16 {
 //
 //	maxItems := config.GetUint32("cache.max_items")
 func GetUint32(key string) uint32 {
-	if val, ok := getEnvValue(key); ok {
+	return std.GetUint32(key)
+}
+
+// GetUint32 is like [GetUint32] but reads from c.
+func (c *Config) GetUint32(key string) uint32 {
+	return c.Snapshot().GetUint32(key)
+}
+
+// GetUint32 is like [GetUint32] but reads from s.
+func (s *Snapshot) GetUint32(key string) uint32 {
+	if val, ok := s.getEnvValue(key); ok {
 		return toUint32(val)
 	}
-	if val, ok := getFromMap(key); ok {
+	if val, ok := s.getFromMap(key); ok {
 		return toUint32(val)
 	}
 	return 0
@@ -273,10 +403,20 @@ func GetUint32(key string) uint32 {
 //
 //	maxPoints := config.GetUint64("metrics.max_data_points")
 func GetUint64(key string) uint64 {
-	if val, ok := getEnvValue(key); ok {
+	return std.GetUint64(key)
+}
+
+// GetUint64 is like [GetUint64] but reads from c.
+func (c *Config) GetUint64(key string) uint64 {
+	return c.Snapshot().GetUint64(key)
+}
+
+// GetUint64 is like [GetUint64] but reads from s.
+func (s *Snapshot) GetUint64(key string) uint64 {
+	if val, ok := s.getEnvValue(key); ok {
 		return toUint64(val)
 	}
-	if val, ok := getFromMap(key); ok {
+	if val, ok := s.getFromMap(key); ok {
 		return toUint64(val)
 	}
 	return 0
@@ -304,10 +444,20 @@ func GetUint64(key string) uint64 {
 //
 //	rate := config.GetFloat64("ml.learning_rate")
 func GetFloat64(key string) float64 {
-	if val, ok := getEnvValue(key); ok {
+	return std.GetFloat64(key)
+}
+
+// GetFloat64 is like [GetFloat64] but reads from c.
+func (c *Config) GetFloat64(key string) float64 {
+	return c.Snapshot().GetFloat64(key)
+}
+
+// GetFloat64 is like [GetFloat64] but reads from s.
+func (s *Snapshot) GetFloat64(key string) float64 {
+	if val, ok := s.getEnvValue(key); ok {
 		return toFloat64(val)
 	}
-	if val, ok := getFromMap(key); ok {
+	if val, ok := s.getFromMap(key); ok {
 		return toFloat64(val)
 	}
 	return 0
@@ -323,6 +473,8 @@ func GetFloat64(key string) float64 {
 //
 // Supported formats:
 //   - Duration strings: "300ms", "1.5s", "2m", "1h30m", "24h"
+//   - Days and weeks: "7d", "2w", "1d12h"
+//   - ISO 8601 durations: "PT30S", "P1DT12H"
 //   - Integer values: treated as nanoseconds
 //
 // Config file example (config.yaml):
@@ -343,10 +495,20 @@ func GetFloat64(key string) float64 {
 //	    ReadTimeout: timeout,
 //	}
 func GetDuration(key string) time.Duration {
-	if val, ok := getEnvValue(key); ok {
+	return std.GetDuration(key)
+}
+
+// GetDuration is like [GetDuration] but reads from c.
+func (c *Config) GetDuration(key string) time.Duration {
+	return c.Snapshot().GetDuration(key)
+}
+
+// GetDuration is like [GetDuration] but reads from s.
+func (s *Snapshot) GetDuration(key string) time.Duration {
+	if val, ok := s.getEnvValue(key); ok {
 		return toDuration(val)
 	}
-	if val, ok := getFromMap(key); ok {
+	if val, ok := s.getFromMap(key); ok {
 		return toDuration(val)
 	}
 	return 0
@@ -370,13 +532,60 @@ func GetDuration(key string) time.Duration {
 //	}
 //	db.Connect(*password)
 func GetStringPtr(key string) *string {
-	if !IsSet(key) {
+	return std.GetStringPtr(key)
+}
+
+// GetStringPtr is like [GetStringPtr] but reads from c.
+func (c *Config) GetStringPtr(key string) *string {
+	return c.Snapshot().GetStringPtr(key)
+}
+
+// GetStringPtr is like [GetStringPtr] but reads from s.
+func (s *Snapshot) GetStringPtr(key string) *string {
+	if !s.IsSet(key) {
 		return nil
 	}
-	value := GetString(key)
+	value := s.GetString(key)
 	return &value
 }
 
+// GetSecret returns the value associated with the given key as a [Secret],
+// which is redacted when printed, logged or marshaled.
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE)
+//  2. Config file value
+//
+// Returns an empty Secret if the key is not found.
+//
+// Config file example (config.yaml):
+//
+//	database:
+//	  password: secret123
+//
+// Environment variable override:
+//
+//	export DATABASE_PASSWORD=othersecret
+//
+// Usage:
+//
+//	password := config.GetSecret("database.password")
+//	slog.Info("connecting", "password", password)  // password=[REDACTED]
+//	db.Connect(password.Reveal())
+func GetSecret(key string) Secret {
+	return std.GetSecret(key)
+}
+
+// GetSecret is like [GetSecret] but reads from c.
+func (c *Config) GetSecret(key string) Secret {
+	return c.Snapshot().GetSecret(key)
+}
+
+// GetSecret is like [GetSecret] but reads from s.
+func (s *Snapshot) GetSecret(key string) Secret {
+	return Secret(s.GetString(key))
+}
+
 // GetStringOr returns the string value associated with the given key,
 // or the defaultValue if the key is not set.
 //
@@ -390,8 +599,18 @@ func GetStringPtr(key string) *string {
 //	env := config.GetStringOr("app.env", "development")  // returns "production"
 //	region := config.GetStringOr("app.region", "us-east-1")  // returns "us-east-1" (not in config)
 func GetStringOr(key string, defaultValue string) string {
-	if IsSet(key) {
-		return GetString(key)
+	return std.GetStringOr(key, defaultValue)
+}
+
+// GetStringOr is like [GetStringOr] but reads from c.
+func (c *Config) GetStringOr(key string, defaultValue string) string {
+	return c.Snapshot().GetStringOr(key, defaultValue)
+}
+
+// GetStringOr is like [GetStringOr] but reads from s.
+func (s *Snapshot) GetStringOr(key string, defaultValue string) string {
+	if s.IsSet(key) {
+		return s.GetString(key)
 	}
 	return defaultValue
 }
@@ -409,8 +628,18 @@ func GetStringOr(key string, defaultValue string) string {
 //	darkMode := config.GetBoolOr("features.dark_mode", false)
 //	analytics := config.GetBoolOr("features.analytics", true)  // defaults to true if not set
 func GetBoolOr(key string, defaultValue bool) bool {
-	if IsSet(key) {
-		return GetBool(key)
+	return std.GetBoolOr(key, defaultValue)
+}
+
+// GetBoolOr is like [GetBoolOr] but reads from c.
+func (c *Config) GetBoolOr(key string, defaultValue bool) bool {
+	return c.Snapshot().GetBoolOr(key, defaultValue)
+}
+
+// GetBoolOr is like [GetBoolOr] but reads from s.
+func (s *Snapshot) GetBoolOr(key string, defaultValue bool) bool {
+	if s.IsSet(key) {
+		return s.GetBool(key)
 	}
 	return defaultValue
 }
@@ -428,8 +657,18 @@ func GetBoolOr(key string, defaultValue bool) bool {
 //	port := config.GetIntOr("http.port", 3000)  // returns 8080
 //	workers := config.GetIntOr("http.workers", 4)  // returns 4 (not in config)
 func GetIntOr(key string, defaultValue int) int {
-	if IsSet(key) {
-		return GetInt(key)
+	return std.GetIntOr(key, defaultValue)
+}
+
+// GetIntOr is like [GetIntOr] but reads from c.
+func (c *Config) GetIntOr(key string, defaultValue int) int {
+	return c.Snapshot().GetIntOr(key, defaultValue)
+}
+
+// GetIntOr is like [GetIntOr] but reads from s.
+func (s *Snapshot) GetIntOr(key string, defaultValue int) int {
+	if s.IsSet(key) {
+		return s.GetInt(key)
 	}
 	return defaultValue
 }
@@ -441,8 +680,18 @@ func GetIntOr(key string, defaultValue int) int {
 //
 //	maxConn := config.GetInt32Or("db.max_connections", 100)
 func GetInt32Or(key string, defaultValue int32) int32 {
-	if IsSet(key) {
-		return GetInt32(key)
+	return std.GetInt32Or(key, defaultValue)
+}
+
+// GetInt32Or is like [GetInt32Or] but reads from c.
+func (c *Config) GetInt32Or(key string, defaultValue int32) int32 {
+	return c.Snapshot().GetInt32Or(key, defaultValue)
+}
+
+// GetInt32Or is like [GetInt32Or] but reads from s.
+func (s *Snapshot) GetInt32Or(key string, defaultValue int32) int32 {
+	if s.IsSet(key) {
+		return s.GetInt32(key)
 	}
 	return defaultValue
 }
@@ -454,8 +703,18 @@ func GetInt32Or(key string, defaultValue int32) int32 {
 //
 //	maxSize := config.GetInt64Or("upload.max_size", 10*1024*1024)  // default 10MB
 func GetInt64Or(key string, defaultValue int64) int64 {
-	if IsSet(key) {
-		return GetInt64(key)
+	return std.GetInt64Or(key, defaultValue)
+}
+
+// GetInt64Or is like [GetInt64Or] but reads from c.
+func (c *Config) GetInt64Or(key string, defaultValue int64) int64 {
+	return c.Snapshot().GetInt64Or(key, defaultValue)
+}
+
+// GetInt64Or is like [GetInt64Or] but reads from s.
+func (s *Snapshot) GetInt64Or(key string, defaultValue int64) int64 {
+	if s.IsSet(key) {
+		return s.GetInt64(key)
 	}
 	return defaultValue
 }
@@ -467,8 +726,18 @@ func GetInt64Or(key string, defaultValue int64) int64 {
 //
 //	poolSize := config.GetUintOr("worker.pool_size", 5)
 func GetUintOr(key string, defaultValue uint) uint {
-	if IsSet(key) {
-		return GetUint(key)
+	return std.GetUintOr(key, defaultValue)
+}
+
+// GetUintOr is like [GetUintOr] but reads from c.
+func (c *Config) GetUintOr(key string, defaultValue uint) uint {
+	return c.Snapshot().GetUintOr(key, defaultValue)
+}
+
+// GetUintOr is like [GetUintOr] but reads from s.
+func (s *Snapshot) GetUintOr(key string, defaultValue uint) uint {
+	if s.IsSet(key) {
+		return s.GetUint(key)
 	}
 	return defaultValue
 }
@@ -480,8 +749,18 @@ func GetUintOr(key string, defaultValue uint) uint {
 //
 //	port := config.GetUint16Or("grpc.port", 50051)
 func GetUint16Or(key string, defaultValue uint16) uint16 {
-	if IsSet(key) {
-		return GetUint16(key)
+	return std.GetUint16Or(key, defaultValue)
+}
+
+// GetUint16Or is like [GetUint16Or] but reads from c.
+func (c *Config) GetUint16Or(key string, defaultValue uint16) uint16 {
+	return c.Snapshot().GetUint16Or(key, defaultValue)
+}
+
+// GetUint16Or is like [GetUint16Or] but reads from s.
+func (s *Snapshot) GetUint16Or(key string, defaultValue uint16) uint16 {
+	if s.IsSet(key) {
+		return s.GetUint16(key)
 	}
 	return defaultValue
 }
@@ -493,8 +772,18 @@ func GetUint16Or(key string, defaultValue uint16) uint16 {
 //
 //	bufferSize := config.GetUint32Or("io.buffer_size", 4096)
 func GetUint32Or(key string, defaultValue uint32) uint32 {
-	if IsSet(key) {
-		return GetUint32(key)
+	return std.GetUint32Or(key, defaultValue)
+}
+
+// GetUint32Or is like [GetUint32Or] but reads from c.
+func (c *Config) GetUint32Or(key string, defaultValue uint32) uint32 {
+	return c.Snapshot().GetUint32Or(key, defaultValue)
+}
+
+// GetUint32Or is like [GetUint32Or] but reads from s.
+func (s *Snapshot) GetUint32Or(key string, defaultValue uint32) uint32 {
+	if s.IsSet(key) {
+		return s.GetUint32(key)
 	}
 	return defaultValue
 }
@@ -506,8 +795,18 @@ func GetUint32Or(key string, defaultValue uint32) uint32 {
 //
 //	maxMemory := config.GetUint64Or("cache.max_memory", 1<<30)  // default 1GB
 func GetUint64Or(key string, defaultValue uint64) uint64 {
-	if IsSet(key) {
-		return GetUint64(key)
+	return std.GetUint64Or(key, defaultValue)
+}
+
+// GetUint64Or is like [GetUint64Or] but reads from c.
+func (c *Config) GetUint64Or(key string, defaultValue uint64) uint64 {
+	return c.Snapshot().GetUint64Or(key, defaultValue)
+}
+
+// GetUint64Or is like [GetUint64Or] but reads from s.
+func (s *Snapshot) GetUint64Or(key string, defaultValue uint64) uint64 {
+	if s.IsSet(key) {
+		return s.GetUint64(key)
 	}
 	return defaultValue
 }
@@ -519,8 +818,18 @@ func GetUint64Or(key string, defaultValue uint64) uint64 {
 //
 //	rate := config.GetFloat64Or("rate_limiter.requests_per_second", 100.0)
 func GetFloat64Or(key string, defaultValue float64) float64 {
-	if IsSet(key) {
-		return GetFloat64(key)
+	return std.GetFloat64Or(key, defaultValue)
+}
+
+// GetFloat64Or is like [GetFloat64Or] but reads from c.
+func (c *Config) GetFloat64Or(key string, defaultValue float64) float64 {
+	return c.Snapshot().GetFloat64Or(key, defaultValue)
+}
+
+// GetFloat64Or is like [GetFloat64Or] but reads from s.
+func (s *Snapshot) GetFloat64Or(key string, defaultValue float64) float64 {
+	if s.IsSet(key) {
+		return s.GetFloat64(key)
 	}
 	return defaultValue
 }
@@ -533,8 +842,18 @@ func GetFloat64Or(key string, defaultValue float64) float64 {
 //	timeout := config.GetDurationOr("http.timeout", 30*time.Second)
 //	cacheTTL := config.GetDurationOr("cache.ttl", 5*time.Minute)
 func GetDurationOr(key string, defaultValue time.Duration) time.Duration {
-	if IsSet(key) {
-		return GetDuration(key)
+	return std.GetDurationOr(key, defaultValue)
+}
+
+// GetDurationOr is like [GetDurationOr] but reads from c.
+func (c *Config) GetDurationOr(key string, defaultValue time.Duration) time.Duration {
+	return c.Snapshot().GetDurationOr(key, defaultValue)
+}
+
+// GetDurationOr is like [GetDurationOr] but reads from s.
+func (s *Snapshot) GetDurationOr(key string, defaultValue time.Duration) time.Duration {
+	if s.IsSet(key) {
+		return s.GetDuration(key)
 	}
 	return defaultValue
 }
@@ -573,10 +892,20 @@ func GetDurationOr(key string, defaultValue time.Duration) time.Duration {
 //	    fmt.Println(origin)
 //	}
 func GetStringSlice(key string) []string {
-	if val, ok := getEnvValue(key); ok {
+	return std.GetStringSlice(key)
+}
+
+// GetStringSlice is like [GetStringSlice] but reads from c.
+func (c *Config) GetStringSlice(key string) []string {
+	return c.Snapshot().GetStringSlice(key)
+}
+
+// GetStringSlice is like [GetStringSlice] but reads from s.
+func (s *Snapshot) GetStringSlice(key string) []string {
+	if val, ok := s.getEnvValue(key); ok {
 		return splitAndTrimStringSlice(val)
 	}
-	if val, ok := getFromMap(key); ok {
+	if val, ok := s.getFromMap(key); ok {
 		return toStringSlice(val)
 	}
 	return nil
@@ -618,10 +947,20 @@ func GetStringSlice(key string) []string {
 //	    time.Sleep(time.Duration(ms) * time.Millisecond)
 //	}
 func GetIntSlice(key string) []int {
-	if val, ok := getEnvValue(key); ok {
+	return std.GetIntSlice(key)
+}
+
+// GetIntSlice is like [GetIntSlice] but reads from c.
+func (c *Config) GetIntSlice(key string) []int {
+	return c.Snapshot().GetIntSlice(key)
+}
+
+// GetIntSlice is like [GetIntSlice] but reads from s.
+func (s *Snapshot) GetIntSlice(key string) []int {
+	if val, ok := s.getEnvValue(key); ok {
 		return splitAndTrimIntSlice(val)
 	}
-	if val, ok := getFromMap(key); ok {
+	if val, ok := s.getFromMap(key); ok {
 		return toIntSlice(val)
 	}
 	return nil
@@ -631,7 +970,9 @@ func GetIntSlice(key string) []int {
 //
 // Returns an empty map if the key is not found.
 //
-// Note: This function does not support environment variable override.
+// Note: This function does not support environment variable override. Use
+// [GetStringMapString], [GetStringMapStringSlice] or [GetSlice] for maps and
+// lists that may be overridden.
 //
 // Config file example (config.yaml):
 //
@@ -647,8 +988,20 @@ func GetIntSlice(key string) []int {
 //	host := dbConfig["host"].(string)
 //	port := dbConfig["port"].(int)
 func GetStringMap(key string) map[string]any {
-	if val, ok := getFromMap(key); ok {
-		return toStringMap(val)
+	return std.GetStringMap(key)
+}
+
+// GetStringMap is like [GetStringMap] but reads from c.
+func (c *Config) GetStringMap(key string) map[string]any {
+	return c.Snapshot().GetStringMap(key)
+}
+
+// GetStringMap is like [GetStringMap] but reads from s.
+func (s *Snapshot) GetStringMap(key string) map[string]any {
+	if val, ok := s.getFromMap(key); ok {
+		// Copy the map, which is shared with the snapshot, and reveal the
+		// secrets of the copy
+		return revealSecrets(deepCopy(toStringMap(val))).(map[string]any)
 	}
 	return map[string]any{}
 }
@@ -687,18 +1040,43 @@ func GetStringMap(key string) map[string]any {
 //	    log.Fatal(err)
 //	}
 //	fmt.Printf("App: %s, Port: %d\n", cfg.App.Name, cfg.HTTP.Port)
+//
+// Fields tagged with `default` get that value when the key is not set, below
+// defaults set via [SetDefault]. Tags only apply to the decoded struct: they
+// do not change the values returned by getters such as [GetInt]:
+//
+//	type HTTPConfig struct {
+//	    Port    int           `yaml:"port" default:"8080"`
+//	    Timeout time.Duration `yaml:"timeout" default:"30s"`
+//	    Origins []string      `yaml:"origins" default:"https://a.com,https://b.com"`
+//	}
+//
+// Fields tagged with `deprecated:"use <new key>"` register the field's key as a
+// deprecated alias of the new key (see [Alias]):
+//
+//	type Config struct {
+//	    DB struct {
+//	        MaxConn int `yaml:"max_conn" deprecated:"use database.pool.max_open"`
+//	    } `yaml:"db"`
+//	}
 func Unmarshal(v any) error {
-	mu.RLock()
-	defer mu.RUnlock()
+	return std.Unmarshal(v)
+}
 
-	// Apply environment variable overrides before unmarshaling
-	configWithOverrides := applyEnvOverrides(configData, "")
+// Unmarshal is like [Unmarshal] but reads from c.
+func (c *Config) Unmarshal(v any) error {
+	return c.Snapshot().Unmarshal(v)
+}
 
-	data, err := yaml.Marshal(configWithOverrides)
-	if err != nil {
-		return err
-	}
-	return yaml.Unmarshal(data, v)
+// Unmarshal is like [Unmarshal] but reads from s.
+func (s *Snapshot) Unmarshal(v any) error {
+	registerStructDeprecations(s, v, "")
+	registerStructSecrets(v, "")
+
+	// Apply defaults and environment variable overrides before unmarshaling
+	configWithOverrides := s.decodeSettings("", structDefaults(v))
+
+	return decodeValue(configWithOverrides, v)
 }
 
 // UnmarshalKey unmarshals a specific configuration section into the provided struct.
@@ -735,29 +1113,125 @@ func Unmarshal(v any) error {
 //	}
 //	fmt.Printf("Connecting to %s:%d/%s\n", dbCfg.Host, dbCfg.Port, dbCfg.Name)
 func UnmarshalKey(key string, v any) error {
-	val, ok := getFromMap(key)
-	if !ok {
+	return std.UnmarshalKey(key, v)
+}
+
+// UnmarshalKey is like [UnmarshalKey] but reads from c.
+func (c *Config) UnmarshalKey(key string, v any) error {
+	return c.Snapshot().UnmarshalKey(key, v)
+}
+
+// UnmarshalKey is like [UnmarshalKey] but reads from s.
+func (s *Snapshot) UnmarshalKey(key string, v any) error {
+	registerStructDeprecations(s, v, key)
+	registerStructSecrets(v, key)
+
+	defaults := structDefaults(v)
+	val, ok := s.getFromMap(key)
+	if !ok && len(defaults) == 0 {
 		return nil
 	}
 
-	// Apply environment variable overrides if val is a map
+	// Apply defaults and environment variable overrides if val is a map
 	var dataToMarshal any
-	if m, ok := val.(map[string]any); ok {
-		dataToMarshal = applyEnvOverrides(m, key)
+	if _, isMap := val.(map[string]any); isMap || !ok {
+		dataToMarshal = s.decodeSettings(key, defaults)
 	} else {
 		// For non-map values, check for env override
-		if envVal, ok := getEnvValue(key); ok {
+		if envVal, ok := s.getEnvValue(key); ok {
 			dataToMarshal = envVal
 		} else {
 			dataToMarshal = val
 		}
 	}
 
-	data, err := yaml.Marshal(dataToMarshal)
-	if err != nil {
+	return decodeValue(dataToMarshal, v)
+}
+
+// decodeValue decodes value, with secrets revealed, into v like yaml.v3 would
+// after marshaling it, except that durations are parsed with ParseDuration
+func decodeValue(value any, v any) error {
+	var node yaml.Node
+	if err := node.Encode(revealSecrets(value)); err != nil {
 		return err
 	}
-	return yaml.Unmarshal(data, v)
+	normalizeScalars(&node, reflect.TypeOf(v))
+	return node.Decode(v)
+}
+
+// normalizeScalars rewrites the scalars of node, given t the type node
+// decodes into, so that yaml.v3 accepts every duration of ParseDuration
+// (e.g., 7d or PT30S) for a time.Duration and a string for a []byte
+func normalizeScalars(node *yaml.Node, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if node.Kind == yaml.DocumentNode {
+		for _, n := range node.Content {
+			normalizeScalars(n, t)
+		}
+		return
+	}
+	if reflect.PointerTo(t).Implements(reflect.TypeFor[yaml.Unmarshaler]()) {
+		return
+	}
+
+	str := node.Kind == yaml.ScalarNode && node.ShortTag() == "!!str"
+	switch {
+	case t == reflect.TypeFor[time.Duration]():
+		if d, err := ParseDuration(node.Value); err == nil && str {
+			node.Value = d.String()
+		}
+	case t == reflect.TypeFor[[]byte]():
+		// yaml.v3 only decodes a []byte from a sequence of bytes
+		if str {
+			data := []byte(node.Value)
+			node.Kind, node.Tag, node.Style, node.Value = yaml.SequenceNode, "!!seq", yaml.FlowStyle, ""
+			node.Content = make([]*yaml.Node, len(data))
+			for i, b := range data {
+				node.Content[i] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(int(b))}
+			}
+		}
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if ft, ok := fields[node.Content[i].Value]; ok {
+				normalizeScalars(node.Content[i+1], ft)
+			}
+		}
+	case t.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			normalizeScalars(node.Content[i], t.Elem())
+		}
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && node.Kind == yaml.SequenceNode:
+		for _, n := range node.Content {
+			normalizeScalars(n, t.Elem())
+		}
+	}
+}
+
+// yamlFields returns the types of the fields of struct type t, by yaml.v3
+// key, including the fields of inlined structs
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, inline, ok := yamlFieldName(field)
+		if !ok {
+			continue
+		}
+		if inline {
+			if field.Type.Kind() == reflect.Struct {
+				maps.Copy(fields, yamlFields(field.Type))
+			}
+			continue
+		}
+		fields[name] = field.Type
+	}
+	return fields
 }
 
 // AllSettings returns a copy of all configuration settings as a map.
@@ -769,6 +1243,14 @@ func UnmarshalKey(key string, v any) error {
 //  1. Environment variable (key converted to UPPER_SNAKE_CASE)
 //  2. Config file value
 //
+// Values of secret keys (see [RedactKeys]) are wrapped in [Secret], so they are
+// redacted when the map is printed, logged or marshaled:
+//
+//	database:
+//	  password: secret123  # returned as config.Secret("secret123")
+//
+// Use [RevealedSettings] to get the actual values.
+//
 // Usage:
 //
 //	settings := config.AllSettings()
@@ -776,14 +1258,87 @@ func UnmarshalKey(key string, v any) error {
 //	    fmt.Printf("%s: %v\n", key, value)
 //	}
 func AllSettings() map[string]any {
-	mu.RLock()
-	defer mu.RUnlock()
+	return std.AllSettings()
+}
 
-	return applyEnvOverrides(configData, "")
+// AllSettings is like [AllSettings] but reads from c.
+func (c *Config) AllSettings() map[string]any {
+	return c.Snapshot().AllSettings()
+}
+
+// AllSettings is like [AllSettings] but reads from s.
+func (s *Snapshot) AllSettings() map[string]any {
+	settings := s.effectiveSettings("")
+	redactSecrets(settings, "")
+	return settings
+}
+
+// RevealedSettings is like [AllSettings], except that the values of secret
+// keys are returned as is, including the values of [Secret] type. Since the
+// map holds passwords and tokens in clear text, it must not be printed or
+// logged.
+//
+// Usage:
+//
+//	settings := config.RevealedSettings()
+//	out, _ := yaml.Marshal(settings)
+//	os.WriteFile("config.resolved.yaml", out, 0600)
+func RevealedSettings() map[string]any {
+	return std.RevealedSettings()
+}
+
+// RevealedSettings is like [RevealedSettings] but reads from c.
+func (c *Config) RevealedSettings() map[string]any {
+	return c.Snapshot().RevealedSettings()
+}
+
+// RevealedSettings is like [RevealedSettings] but reads from s.
+func (s *Snapshot) RevealedSettings() map[string]any {
+	return revealSecrets(s.effectiveSettings("")).(map[string]any)
+}
+
+// effectiveSettings returns a deep copy of the configuration section at prefix
+// (or the entire configuration if prefix is empty) with the tree layers merged,
+// deprecated aliases resolved, defaults filled in and environment variable
+// overrides applied
+func (s *Snapshot) effectiveSettings(prefix string) map[string]any {
+	return s.applyEnvOverrides(s.treeSettings(prefix), prefix)
+}
+
+// treeSettings is like effectiveSettings but ignores environment variables
+func (s *Snapshot) treeSettings(prefix string) map[string]any {
+	data := map[string]any{}
+	defaults := map[string]any{}
+
+	for _, l := range s.layers {
+		switch l.kind {
+		case treeLayer:
+			mergeOver(data, sectionCopy(l.data, prefix))
+		case defaultLayer:
+			mergeOver(defaults, sectionCopy(l.data, prefix))
+		}
+	}
+
+	s.applyAliases(data, prefix)
+	mergeMissing(data, defaults)
+	return data
+}
+
+// sectionCopy returns a deep copy of the nested map at prefix, or an empty map
+// if there is none
+func sectionCopy(data map[string]any, prefix string) map[string]any {
+	var section any = data
+	if prefix != "" {
+		section, _ = getPath(data, prefix)
+	}
+	if m, ok := section.(map[string]any); ok {
+		return deepCopy(m).(map[string]any)
+	}
+	return map[string]any{}
 }
 
 // applyEnvOverrides recursively applies environment variable overrides to a map
-func applyEnvOverrides(data map[string]any, prefix string) map[string]any {
+func (s *Snapshot) applyEnvOverrides(data map[string]any, prefix string) map[string]any {
 	result := make(map[string]any)
 
 	for k, v := range data {
@@ -795,10 +1350,26 @@ func applyEnvOverrides(data map[string]any, prefix string) map[string]any {
 		switch val := v.(type) {
 		case map[string]any:
 			// Recursively process nested maps
-			result[k] = applyEnvOverrides(val, key)
+			result[k] = s.applyEnvOverrides(val, key)
+		case []any:
+			if envVal, ok := s.getEnvValue(key); ok {
+				result[k] = convertEnvToType(envVal, v)
+			} else if s.envAbove(key) {
+				// Override fields of list items, e.g. UPSTREAMS_0_URL
+				items := make([]any, len(val))
+				for i, item := range val {
+					items[i] = item
+					if m, ok := item.(map[string]any); ok {
+						items[i] = s.applyEnvOverrides(m, key+"."+strconv.Itoa(i))
+					}
+				}
+				result[k] = items
+			} else {
+				result[k] = v
+			}
 		default:
 			// Check for environment variable override
-			if envVal, ok := getEnvValue(key); ok {
+			if envVal, ok := s.getEnvValue(key); ok {
 				// Convert env string to match original value's type
 				result[k] = convertEnvToType(envVal, v)
 			} else {
@@ -831,6 +1402,8 @@ func convertEnvToType(envVal string, originalVal any) any {
 		return toUint64(envVal)
 	case float32, float64:
 		return toFloat64(envVal)
+	case Secret:
+		return Secret(envVal)
 	case []string:
 		return splitAndTrimStringSlice(envVal)
 	case []int:
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	includeMu   sync.RWMutex
+	includeDirs []string // directories allowed in addition to the config directory
+)
+
+// AllowIncludes allows !include and !include_optional to read files under the
+// given directories, in addition to the directory tree of config.yaml.
+//
+// Config files may include other YAML files, resolved relative to the including
+// file. The included document replaces the tagged value; a missing file is an
+// error for !include, while !include_optional drops the key:
+//
+//	tracing: !include shared/tracing.yaml
+//	logging: !include_optional /etc/myapp/logging.yaml
+//
+// Include cycles are detected and reported as errors.
+//
+// Usage:
+//
+//	config.AllowIncludes("/etc/myapp")
+//	config.Init()
+func AllowIncludes(dirs ...string) {
+	includeMu.Lock()
+	defer includeMu.Unlock()
+	for _, dir := range dirs {
+		if abs, err := filepath.Abs(dir); err == nil {
+			includeDirs = append(includeDirs, abs)
+		}
+	}
+}
+
+// resetIncludes forgets the directories allowed by AllowIncludes.
+func resetIncludes() {
+	includeMu.Lock()
+	defer includeMu.Unlock()
+	includeDirs = nil
+}
+
+// includeResolver replaces !include and !include_optional nodes with the
+// content of the included files
+type includeResolver struct {
+	roots []string              // directories includes are confined to
+	stack []string              // files being included, outermost first
+	files map[*yaml.Node]string // file of every included node
+}
+
+// newIncludeResolver returns a resolver confining includes to root and to the
+// directories allowed by AllowIncludes
+func newIncludeResolver(root string) *includeResolver {
+	includeMu.RLock()
+	defer includeMu.RUnlock()
+
+	r := &includeResolver{files: make(map[*yaml.Node]string)}
+	for _, dir := range append([]string{root}, includeDirs...) {
+		if abs, err := filepath.Abs(dir); err == nil {
+			r.roots = append(r.roots, realPath(abs))
+		}
+	}
+	return r
+}
+
+// parseFile parses the YAML file at path, resolving its includes
+func (r *includeResolver) parseFile(path string) (*yaml.Node, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	abs = realPath(abs)
+	if slices.Contains(r.stack, abs) {
+		return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(r.stack, " -> "), abs)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	r.stack = append(r.stack, abs)
+	defer func() { r.stack = r.stack[:len(r.stack)-1] }()
+	if err := r.resolve(&doc, path); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// resolve replaces the include nodes found under node, which belongs to file.
+// Keys and items whose value is an optional include of a missing file are removed.
+func (r *includeResolver) resolve(node *yaml.Node, file string) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for i, child := range node.Content {
+			included, ok, err := r.include(child, file)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				included = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			}
+			node.Content[i] = included
+		}
+	case yaml.MappingNode:
+		content := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			included, ok, err := r.include(value, file)
+			if err != nil {
+				return err
+			}
+			if ok {
+				content = append(content, key, included)
+			}
+		}
+		node.Content = content
+	case yaml.SequenceNode:
+		content := node.Content[:0]
+		for _, item := range node.Content {
+			included, ok, err := r.include(item, file)
+			if err != nil {
+				return err
+			}
+			if ok {
+				content = append(content, included)
+			}
+		}
+		node.Content = content
+	}
+	return nil
+}
+
+// include returns node with its includes resolved, or false if node is an
+// optional include of a missing file
+func (r *includeResolver) include(node *yaml.Node, file string) (*yaml.Node, bool, error) {
+	if node.Tag != "!include" && node.Tag != "!include_optional" {
+		return node, true, r.resolve(node, file)
+	}
+	if node.Kind != yaml.ScalarNode || node.Value == "" {
+		return nil, false, fmt.Errorf("%s:%d: %s requires a file path", file, node.Line, node.Tag)
+	}
+
+	path := node.Value
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(file), path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s:%d: %w", file, node.Line, err)
+	}
+	if !r.allowed(realPath(abs)) {
+		return nil, false, fmt.Errorf("%s:%d: include %s is outside the config directory (see AllowIncludes)", file, node.Line, node.Value)
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) && node.Tag == "!include_optional" {
+		return nil, false, nil
+	}
+	doc, err := r.parseFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s:%d: include %s: %w", file, node.Line, node.Value, err)
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Line: node.Line}, true, nil
+	}
+	r.files[doc.Content[0]] = path
+	return doc.Content[0], true, nil
+}
+
+// allowed reports whether path is inside one of the include roots
+func (r *includeResolver) allowed(path string) bool {
+	for _, root := range r.roots {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// realPath returns path with symbolic links evaluated, or path itself if it
+// does not exist
+func realPath(path string) string {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	return path
+}
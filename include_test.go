@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInclude(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "shared"), 0755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	writeTestFile(t, dir, "shared/tracing.yaml", "enabled: true\nexporter: !include exporter.yaml\n")
+	writeTestFile(t, dir, "shared/exporter.yaml", "endpoint: otel:4317\n")
+
+	t.Run("includes are resolved relative to the including file", func(t *testing.T) {
+		Reset()
+		path := writeTestFile(t, dir, "config.yaml", "app:\n  name: myapp\ntracing: !include shared/tracing.yaml\n")
+
+		cfg, err := New(WithSources(File(path)))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if !cfg.GetBool("tracing.enabled") {
+			t.Error("GetBool(tracing.enabled) = false, want true")
+		}
+		if got := cfg.GetString("tracing.exporter.endpoint"); got != "otel:4317" {
+			t.Errorf("GetString(tracing.exporter.endpoint) = %q, want %q", got, "otel:4317")
+		}
+		if got := cfg.GetString("app.name"); got != "myapp" {
+			t.Errorf("GetString(app.name) = %q, want %q", got, "myapp")
+		}
+	})
+
+	t.Run("optional include of a missing file", func(t *testing.T) {
+		Reset()
+		path := writeTestFile(t, dir, "config.yaml", "logging: !include_optional shared/missing.yaml\nhosts:\n  - a\n  - !include_optional shared/missing.yaml\n")
+
+		cfg, err := New(WithSources(File(path)))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if cfg.IsSet("logging") {
+			t.Error("IsSet(logging) = true, want false")
+		}
+		if got := cfg.GetStringSlice("hosts"); len(got) != 1 {
+			t.Errorf("GetStringSlice(hosts) = %v, want [a]", got)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		Reset()
+		path := writeTestFile(t, dir, "config.yaml", "app:\n  name: myapp\nlogging: !include shared/missing.yaml\n")
+
+		_, err := New(WithSources(File(path)))
+		if err == nil || !strings.Contains(err.Error(), "config.yaml:3") {
+			t.Errorf("New() error = %v, want error at config.yaml:3", err)
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		Reset()
+		writeTestFile(t, dir, "shared/a.yaml", "b: !include b.yaml\n")
+		writeTestFile(t, dir, "shared/b.yaml", "a: !include a.yaml\n")
+		path := writeTestFile(t, dir, "config.yaml", "a: !include shared/a.yaml\n")
+
+		_, err := New(WithSources(File(path)))
+		if err == nil || !strings.Contains(err.Error(), "include cycle") {
+			t.Errorf("New() error = %v, want include cycle", err)
+		}
+	})
+
+	t.Run("outside the config directory", func(t *testing.T) {
+		Reset()
+		outside := t.TempDir()
+		writeTestFile(t, outside, "logging.yaml", "level: debug\n")
+		path := writeTestFile(t, dir, "config.yaml", "logging: !include "+filepath.Join(outside, "logging.yaml")+"\n")
+
+		_, err := New(WithSources(File(path)))
+		if err == nil || !strings.Contains(err.Error(), "outside the config directory") {
+			t.Errorf("New() error = %v, want outside the config directory", err)
+		}
+
+		AllowIncludes(outside)
+		cfg, err := New(WithSources(File(path)))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if got := cfg.GetString("logging.level"); got != "debug" {
+			t.Errorf("GetString(logging.level) = %q, want %q", got, "debug")
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// KeyType is the set of value types a [Key] can be read as.
+type KeyType interface {
+	string | bool | int | int32 | int64 | uint | uint16 | uint32 | uint64 | float64 |
+		time.Duration | Secret | []string | []int
+}
+
+// Key is a handle to a configuration key, read as a value of type T.
+//
+// Keys are meant to be declared once, as package-level variables, and read on
+// hot paths (e.g., per request): the key's path and environment variable name
+// are computed when the Key is created, so [Key.Get] does not look them up
+// and, for a scalar type, does not allocate.
+//
+// Usage:
+//
+//	var httpPort = config.NewKey[int]("http.port")
+//	var readTimeout = config.NewKey[time.Duration]("http.read_timeout")
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//	    ctx, cancel := context.WithTimeout(r.Context(), readTimeout.Get())
+//	    defer cancel()
+//	    // ...
+//	}
+type Key[T KeyType] struct {
+	info      *keyInfo
+	fromEnv   func(string) T // converts an environment variable
+	fromValue func(any) T    // converts a value of the other layers
+}
+
+// NewKey returns a [Key] for the given dot notation key. The value is
+// converted like the getter of the same type (e.g., [GetInt] for int).
+func NewKey[T KeyType](key string) *Key[T] {
+	var fromEnv, fromValue any
+	switch any(*new(T)).(type) {
+	case string:
+		fromEnv, fromValue = func(v string) string { return v }, toString
+	case bool:
+		fromEnv, fromValue = envConverter(toBool), toBool
+	case int:
+		fromEnv, fromValue = envConverter(toInt), toInt
+	case int32:
+		fromEnv, fromValue = envConverter(toInt32), toInt32
+	case int64:
+		fromEnv, fromValue = envConverter(toInt64), toInt64
+	case uint:
+		fromEnv, fromValue = envConverter(toUint), toUint
+	case uint16:
+		fromEnv, fromValue = envConverter(toUint16), toUint16
+	case uint32:
+		fromEnv, fromValue = envConverter(toUint32), toUint32
+	case uint64:
+		fromEnv, fromValue = envConverter(toUint64), toUint64
+	case float64:
+		fromEnv, fromValue = envConverter(toFloat64), toFloat64
+	case time.Duration:
+		fromEnv, fromValue = envConverter(toDuration), toDuration
+	case Secret:
+		fromEnv = func(v string) Secret { return Secret(v) }
+		fromValue = func(v any) Secret { return Secret(toString(v)) }
+	case []string:
+		fromEnv, fromValue = splitAndTrimStringSlice, toStringSlice
+	case []int:
+		fromEnv, fromValue = splitAndTrimIntSlice, toIntSlice
+	}
+	return &Key[T]{
+		info:      lookupKey(key),
+		fromEnv:   fromEnv.(func(string) T),
+		fromValue: fromValue.(func(any) T),
+	}
+}
+
+// envConverter returns a converter of environment variables calling conv
+func envConverter[T any](conv func(any) T) func(string) T {
+	return func(v string) T {
+		return conv(v)
+	}
+}
+
+// Name returns the dot notation key of k.
+func (k *Key[T]) Name() string {
+	return k.info.key
+}
+
+// Get returns the value of k in the default Config.
+func (k *Key[T]) Get() T {
+	return k.From(std.Snapshot())
+}
+
+// From returns the value of k in the given snapshot (see [Config.Snapshot]).
+func (k *Key[T]) From(s *Snapshot) T {
+	if val, ok := s.envValue(k.info); ok {
+		return k.fromEnv(val)
+	}
+	if val, _, ok := s.findKey(k.info, true); ok {
+		return k.fromValue(revealValue(val))
+	}
+	var zero T
+	return zero
+}
+
+// keyInfo holds the precomputed forms of a dot notation key
+type keyInfo struct {
+	key  string   // dot notation key, e.g. db.host
+	env  string   // environment variable name, e.g. DB_HOST
+	path []string // key split on dots
+}
+
+// maxKeyTable limits the number of keys memoized by lookupKey
+const maxKeyTable = 10000
+
+var (
+	keyTable     sync.Map // key -> *keyInfo
+	keyTableSize atomic.Int64
+)
+
+// lookupKey returns the precomputed forms of key, computing them on first use
+func lookupKey(key string) *keyInfo {
+	if info, ok := keyTable.Load(key); ok {
+		return info.(*keyInfo)
+	}
+
+	info := &keyInfo{
+		key:  key,
+		env:  strings.ToUpper(strings.ReplaceAll(key, ".", "_")),
+		path: strings.Split(key, "."),
+	}
+	if keyTableSize.Load() >= maxKeyTable {
+		return info
+	}
+	if existing, loaded := keyTable.LoadOrStore(key, info); loaded {
+		return existing.(*keyInfo)
+	}
+	keyTableSize.Add(1)
+	return info
+}
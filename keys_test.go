@@ -0,0 +1,121 @@
+package config
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	t.Run("reads the default Config", func(t *testing.T) {
+		Reset()
+		Set("http.port", 8080)
+		Set("http.timeout", "5s")
+		Set("http.hosts", []string{"a", "b"})
+
+		if got := NewKey[int]("http.port").Get(); got != 8080 {
+			t.Errorf("Get() = %v, want %v", got, 8080)
+		}
+		if got := NewKey[string]("http.port").Get(); got != "8080" {
+			t.Errorf("Get() = %q, want %q", got, "8080")
+		}
+		if got := NewKey[time.Duration]("http.timeout").Get(); got != 5*time.Second {
+			t.Errorf("Get() = %v, want %v", got, 5*time.Second)
+		}
+		if got := NewKey[[]string]("http.hosts").Get(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+			t.Errorf("Get() = %v, want %v", got, []string{"a", "b"})
+		}
+
+		os.Setenv("HTTP_PORT", "3000")
+		defer os.Unsetenv("HTTP_PORT")
+		if got := NewKey[int]("http.port").Get(); got != 3000 {
+			t.Errorf("Get() with env = %v, want %v", got, 3000)
+		}
+	})
+
+	t.Run("reads a snapshot", func(t *testing.T) {
+		cfg, err := New(WithSources(Map("base", map[string]any{"http.port": 9000})))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		port := NewKey[int]("http.port")
+		if got := port.From(cfg.Snapshot()); got != 9000 {
+			t.Errorf("From() = %v, want %v", got, 9000)
+		}
+		if got := port.Name(); got != "http.port" {
+			t.Errorf("Name() = %q, want %q", got, "http.port")
+		}
+	})
+
+	t.Run("aliases registered after the Key", func(t *testing.T) {
+		Reset()
+		maxOpen := NewKey[int]("database.pool.max_open")
+		secret := NewKey[Secret]("database.password")
+		Set("db.max_conn", 50)
+		Set("database.password", "hunter2")
+
+		Alias("db.max_conn", "database.pool.max_open")
+		if got := maxOpen.Get(); got != 50 {
+			t.Errorf("Get() = %v, want %v", got, 50)
+		}
+		if got := secret.Get(); got != Secret("hunter2") {
+			t.Errorf("Get() = %q, want %q", got.Reveal(), "hunter2")
+		}
+	})
+}
+
+func TestKeyAllocs(t *testing.T) {
+	os.Setenv("TEST_KEY_ALLOCS_TIMEOUT", "5s")
+	defer os.Unsetenv("TEST_KEY_ALLOCS_TIMEOUT")
+
+	cfg, err := New(WithSources(
+		Map("base", map[string]any{"http.port": 8080, "app.name": "myapp"}),
+		Env(),
+	))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := cfg.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	snap := cfg.Snapshot()
+
+	port := NewKey[int]("http.port")
+	name := NewKey[string]("app.name")
+	timeout := NewKey[time.Duration]("test_key_allocs.timeout")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = port.From(snap)
+		_ = name.From(snap)
+		_ = timeout.From(snap)
+	})
+	if allocs != 0 {
+		t.Errorf("Key.From allocates %v times, want 0", allocs)
+	}
+}
+
+func BenchmarkKey(b *testing.B) {
+	cfg, err := New(WithSources(Map("base", map[string]any{"http.port": 8080}), Env()))
+	if err != nil {
+		b.Fatalf("New() error = %v", err)
+	}
+	snap := cfg.Snapshot()
+	port := NewKey[int]("http.port")
+
+	b.Run("Key.From", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = port.From(snap)
+		}
+	})
+
+	b.Run("GetInt", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = cfg.GetInt("http.port")
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// LintRule identifies the rule violated by a [LintIssue].
+type LintRule string
+
+// Rules checked by [Lint].
+const (
+	EnvCollision LintRule = "env-collision" // keys overridden by the same environment variable
+	KeyNaming    LintRule = "key-naming"    // keys that are not snake_case
+	OSVariable   LintRule = "os-variable"   // keys overridden by well-known OS variables
+	MixedList    LintRule = "mixed-list"    // lists that environment variables cannot override faithfully
+)
+
+// LintIssue is a problem with a configuration key found by [Lint].
+type LintIssue struct {
+	Key     string
+	Rule    LintRule
+	Message string
+}
+
+// String returns the issue as "key: message (rule)".
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s: %s (%s)", i.Key, i.Message, i.Rule)
+}
+
+// wellKnownEnv are environment variables set by the OS or the shell, which
+// must not double as configuration overrides.
+var wellKnownEnv = map[string]bool{
+	"EDITOR": true, "HOME": true, "HOSTNAME": true, "LANG": true, "LOGNAME": true,
+	"MAIL": true, "OLDPWD": true, "PATH": true, "PWD": true, "SHELL": true,
+	"SHLVL": true, "TERM": true, "TMPDIR": true, "TZ": true, "USER": true,
+}
+
+// snakeCase matches a valid segment of a key
+var snakeCase = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// Lint checks the known configuration keys for problems with their
+// environment variable overrides, sorted by key:
+//
+//   - [EnvCollision]: keys that map to the same variable (e.g.,
+//     app.service_name and app.service.name both map to APP_SERVICE_NAME)
+//   - [KeyNaming]: keys that are not snake_case (e.g., app.serviceName maps to
+//     the unreadable APP_SERVICENAME)
+//   - [OSVariable]: keys overridden by well-known OS variables (e.g., path
+//     and home are overridden by PATH and HOME)
+//   - [MixedList]: lists whose elements are not all strings, all integers or
+//     all maps, which lose elements when overridden by a comma-separated
+//     variable (lists of maps are overridden by index, see [GetSlice])
+//
+// Known keys are the keys of the loaded configuration (including defaults)
+// and the fields of the optional structs, which use the same `yaml` tags as
+// [Unmarshal].
+//
+// Config file example (config.yaml):
+//
+//	app:
+//	  serviceName: api
+//	  service:
+//	    name: api
+//
+// Usage:
+//
+//	for _, issue := range config.Lint(&Config{}) {
+//	    log.Println(issue)  // app.serviceName: key is not snake_case (key-naming)
+//	}
+func Lint(structs ...any) []LintIssue {
+	return std.Lint(structs...)
+}
+
+// Lint is like [Lint] but reads from c.
+func (c *Config) Lint(structs ...any) []LintIssue {
+	return c.Snapshot().Lint(structs...)
+}
+
+// Lint is like [Lint] but reads from s.
+func (s *Snapshot) Lint(structs ...any) []LintIssue {
+	var issues []LintIssue
+
+	for _, ev := range s.EnvVars(structs...) {
+		for _, other := range ev.Collisions {
+			issues = append(issues, LintIssue{
+				Key:     ev.Key,
+				Rule:    EnvCollision,
+				Message: fmt.Sprintf("%s also overrides %s", ev.Name, other),
+			})
+		}
+		if wellKnownEnv[ev.Name] {
+			issues = append(issues, LintIssue{
+				Key:     ev.Key,
+				Rule:    OSVariable,
+				Message: fmt.Sprintf("overridden by the OS variable %s", ev.Name),
+			})
+		}
+	}
+
+	keys := make(map[string]bool)
+	walkLeaves(s.treeSettings(""), "", func(key string, value any) {
+		keys[key] = true
+		if msg := lintList(value); msg != "" {
+			issues = append(issues, LintIssue{Key: key, Rule: MixedList, Message: msg})
+		}
+	})
+	for _, v := range structs {
+		walkStructFields(v, "", func(key string, field reflect.StructField) {
+			keys[key] = true
+		})
+	}
+
+	named := make(map[string]bool)
+	for key := range keys {
+		parts := strings.Split(key, ".")
+		for i, part := range parts {
+			prefix := strings.Join(parts[:i+1], ".")
+			if named[prefix] || snakeCase.MatchString(part) {
+				continue
+			}
+			named[prefix] = true
+			issues = append(issues, LintIssue{Key: prefix, Rule: KeyNaming, Message: "key is not snake_case"})
+		}
+	}
+
+	slices.SortFunc(issues, func(a, b LintIssue) int {
+		return cmp.Or(cmp.Compare(a.Key, b.Key), cmp.Compare(a.Rule, b.Rule), cmp.Compare(a.Message, b.Message))
+	})
+	return issues
+}
+
+// lintList describes how an environment variable override would mangle the
+// list value, or returns "" if it would not
+func lintList(value any) string {
+	list, ok := value.([]any)
+	if !ok || len(list) == 0 {
+		return ""
+	}
+
+	kinds := make(map[string]bool)
+	for _, item := range list {
+		switch item.(type) {
+		case string:
+			kinds["string"] = true
+		case int, int64:
+			kinds["int"] = true
+		case float64:
+			kinds["float"] = true
+		case bool:
+			kinds["bool"] = true
+		case map[string]any:
+			kinds["map"] = true // fields are overridden by index, see GetSlice
+		default:
+			return "list of lists cannot be overridden by an environment variable"
+		}
+	}
+	if kinds["map"] {
+		if len(kinds) > 1 {
+			return "list mixes maps and scalars, which environment variables cannot override consistently"
+		}
+		return ""
+	}
+	if len(kinds) > 1 {
+		return "list has mixed element types, which an environment variable override converts like the first element"
+	}
+	if kinds["float"] {
+		return "list of floats loses non-integer elements when overridden by an environment variable"
+	}
+	return ""
+}
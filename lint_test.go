@@ -0,0 +1,57 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	t.Run("clean configuration", func(t *testing.T) {
+		Reset()
+		Set("app.service_name", "api")
+		Set("http.hosts", []any{"a", "b"})
+		Set("http.ports", []any{80, 443})
+
+		if issues := Lint(); len(issues) != 0 {
+			t.Errorf("Lint() = %v, want none", issues)
+		}
+	})
+
+	t.Run("issues", func(t *testing.T) {
+		Reset()
+		Set("app.service_name", "api")
+		Set("app.service.name", "api")
+		Set("app.serviceName", "api")
+		Set("path", "/srv")
+		Set("ratios", []any{0.5, 1.5})
+		Set("mixed", []any{1, "two"})
+		Set("servers", []any{map[string]any{"name": "a"}})
+		Set("matrix", []any{[]any{1, 2}})
+		Set("hosts", []any{"a", map[string]any{"name": "b"}})
+
+		type AppConfig struct {
+			Cache struct {
+				MaxSize int `yaml:"MaxSize"`
+			} `yaml:"cache"`
+		}
+
+		var got []string
+		for _, issue := range Lint(&AppConfig{}) {
+			got = append(got, issue.String())
+		}
+		want := []string{
+			"app.service.name: APP_SERVICE_NAME also overrides app.service_name (env-collision)",
+			"app.serviceName: key is not snake_case (key-naming)",
+			"app.service_name: APP_SERVICE_NAME also overrides app.service.name (env-collision)",
+			"cache.MaxSize: key is not snake_case (key-naming)",
+			"hosts: list mixes maps and scalars, which environment variables cannot override consistently (mixed-list)",
+			"matrix: list of lists cannot be overridden by an environment variable (mixed-list)",
+			"mixed: list has mixed element types, which an environment variable override converts like the first element (mixed-list)",
+			"path: overridden by the OS variable PATH (os-variable)",
+			"ratios: list of floats loses non-integer elements when overridden by an environment variable (mixed-list)",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Lint() =\n%v\nwant\n%v", got, want)
+		}
+	})
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Live holds the current value of a configuration key, decoded as a T and
+// updated every time the configuration changes (see [Watch]).
+type Live[T any] struct {
+	key   string
+	value atomic.Pointer[T]
+
+	err atomic.Pointer[error] // error of the last decode, if any
+
+	mu     sync.Mutex // serializes updates
+	subs   []func(T)
+	closed bool
+	stop   func() // unregisters the watcher of l
+}
+
+// Watch returns a [Live] value for the given key of the default Config, so
+// long-running components can pick up changes without a restart.
+//
+// The value is decoded like [UnmarshalKey], so T may be a scalar, a slice or
+// a struct. If the value cannot be decoded after a change (e.g., "abc" for an
+// int), the previous value is kept and the error is logged (see [SetLogger])
+// and returned by [Live.Err].
+//
+// Call [Live.Close] when the value is no longer needed, so that the Config
+// stops updating it.
+//
+// Config file example (config.yaml):
+//
+//	log:
+//	  level: info
+//	ratelimit:
+//	  rps: 100
+//
+// Usage:
+//
+//	level := config.Watch[string]("log.level")
+//	level.Subscribe(func(l string) {
+//	    logger.SetLevel(l)
+//	})
+//
+//	rps := config.Watch[int]("ratelimit.rps")
+//	defer rps.Close()
+//	limiter.Allow(rps.Load())
+func Watch[T any](key string) *Live[T] {
+	return WatchConfig[T](std, key)
+}
+
+// WatchConfig is like [Watch] but watches a key of c.
+func WatchConfig[T any](c *Config, key string) *Live[T] {
+	l := &Live[T]{key: key}
+	w := &watcher{fn: func() {
+		l.refresh(c.Snapshot())
+	}}
+	l.stop = func() {
+		c.watchMu.Lock()
+		defer c.watchMu.Unlock()
+		c.watchers = slices.DeleteFunc(c.watchers, func(other *watcher) bool {
+			return other == w
+		})
+	}
+
+	// Registered before the first refresh, so that no update is missed
+	c.watchMu.Lock()
+	c.watchers = append(c.watchers, w)
+	c.watchMu.Unlock()
+
+	l.refresh(c.Snapshot())
+	return l
+}
+
+// Close stops updating l and calling its subscribers. [Live.Load] keeps
+// returning the last value. Close may be called more than once.
+func (l *Live[T]) Close() {
+	l.stop()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	l.subs = nil
+}
+
+// Key returns the key watched by l.
+func (l *Live[T]) Key() string {
+	return l.key
+}
+
+// Load returns the current value. It is safe for concurrent use and does not
+// take locks.
+func (l *Live[T]) Load() T {
+	if v := l.value.Load(); v != nil {
+		return *v
+	}
+	var zero T
+	return zero
+}
+
+// Subscribe registers fn to be called with the new value every time it
+// changes. Callbacks run on the goroutine that changed the configuration, one
+// at a time; they may call [Live.Load] and [Live.Err] but not Subscribe.
+func (l *Live[T]) Subscribe(fn func(T)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.subs = append(l.subs, fn)
+}
+
+// Err returns the error of the last decode, or nil if it succeeded.
+func (l *Live[T]) Err() error {
+	if err := l.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// refresh decodes the value of the key in s and notifies the subscribers if
+// it changed
+func (l *Live[T]) refresh(s *Snapshot) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+
+	v, err := decodeKey[T](s, l.key)
+	if err != nil {
+		err = fmt.Errorf("config: watch %s: %w", l.key, err)
+		l.err.Store(&err)
+		aliasMu.RLock()
+		if logger != nil {
+			logger.Printf("%s (keeping the previous value)", err)
+		}
+		aliasMu.RUnlock()
+		return
+	}
+	l.err.Store(nil)
+
+	if old := l.value.Load(); old != nil && reflect.DeepEqual(*old, v) {
+		return
+	}
+	l.value.Store(&v)
+	for _, fn := range l.subs {
+		fn(v)
+	}
+}
+
+// decodeKey decodes the value of key in s as a T. Unlike the getters, values
+// that cannot be converted are errors. A missing key decodes as the zero value.
+// Environment variables are converted according to T, so that strings such
+// as "1.10" or "0123" are kept as is.
+func decodeKey[T any](s *Snapshot, key string) (T, error) {
+	var v T
+
+	// Sections, and missing sections decoded into a struct, are decoded like
+	// UnmarshalKey to apply `default` tags
+	val, inMap := s.getFromMap(key)
+	_, isMap := val.(map[string]any)
+	if isMap || (!inMap && reflect.TypeFor[T]().Kind() == reflect.Struct) {
+		err := s.UnmarshalKey(key, &v)
+		return v, err
+	}
+
+	var raw any
+	if envVal, ok := s.getEnvValue(key); ok {
+		raw = parseDefault(envVal, reflect.TypeFor[T]())
+	} else if inMap {
+		raw = val
+	} else {
+		return v, nil
+	}
+
+	err := decodeValue(raw, &v)
+	return v, err
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	t.Run("scalar value", func(t *testing.T) {
+		Reset()
+		Set("log.level", "info")
+
+		level := Watch[string]("log.level")
+		var got []string
+		level.Subscribe(func(l string) {
+			got = append(got, l)
+		})
+
+		if v := level.Load(); v != "info" {
+			t.Errorf("Load() = %q, want %q", v, "info")
+		}
+
+		Set("log.level", "debug")
+		Set("app.name", "unrelated")
+		if v := level.Load(); v != "debug" {
+			t.Errorf("Load() = %q, want %q", v, "debug")
+		}
+		if len(got) != 1 || got[0] != "debug" {
+			t.Errorf("Subscribe callbacks = %v, want [debug]", got)
+		}
+	})
+
+	t.Run("struct value", func(t *testing.T) {
+		Reset()
+		Set("ratelimit", map[string]any{"rps": 100, "burst": 10})
+
+		type RateLimit struct {
+			RPS   int `yaml:"rps"`
+			Burst int `yaml:"burst"`
+		}
+		rl := Watch[RateLimit]("ratelimit")
+		if v := rl.Load(); v.RPS != 100 || v.Burst != 10 {
+			t.Errorf("Load() = %+v, want {RPS:100 Burst:10}", v)
+		}
+
+		Set("ratelimit.rps", 200)
+		if v := rl.Load(); v.RPS != 200 || v.Burst != 10 {
+			t.Errorf("Load() = %+v, want {RPS:200 Burst:10}", v)
+		}
+	})
+
+	t.Run("default tags", func(t *testing.T) {
+		Reset()
+
+		type HTTPConfig struct {
+			Port    int           `yaml:"port" default:"8080"`
+			Timeout time.Duration `yaml:"timeout" default:"30s"`
+		}
+		http := Watch[HTTPConfig]("http")
+		defer http.Close()
+		if v := http.Load(); v.Port != 8080 || v.Timeout != 30*time.Second {
+			t.Errorf("Load() = %+v, want {Port:8080 Timeout:30s}", v)
+		}
+
+		Set("http.port", 9090)
+		if v := http.Load(); v.Port != 9090 || v.Timeout != 30*time.Second {
+			t.Errorf("Load() = %+v, want {Port:9090 Timeout:30s}", v)
+		}
+	})
+
+	t.Run("decode failure keeps the previous value", func(t *testing.T) {
+		Reset()
+		l := &recordingLogger{}
+		SetLogger(l)
+		defer SetLogger(log.Default())
+
+		Set("ratelimit.rps", 100)
+		rps := Watch[int]("ratelimit.rps")
+
+		Set("ratelimit.rps", "abc")
+		if v := rps.Load(); v != 100 {
+			t.Errorf("Load() = %v, want %v", v, 100)
+		}
+		if rps.Err() == nil {
+			t.Error("Err() = nil, want error")
+		}
+		if len(l.messages) != 1 {
+			t.Errorf("logged %v, want 1 message", l.messages)
+		}
+
+		Set("ratelimit.rps", 300)
+		if v := rps.Load(); v != 300 {
+			t.Errorf("Load() = %v, want %v", v, 300)
+		}
+		if err := rps.Err(); err != nil {
+			t.Errorf("Err() = %v, want nil", err)
+		}
+	})
+
+	t.Run("environment variables are converted according to T", func(t *testing.T) {
+		Reset()
+		os.Setenv("APP_VERSION", "1.10")
+		defer os.Unsetenv("APP_VERSION")
+		os.Setenv("APP_CODE", "0123")
+		defer os.Unsetenv("APP_CODE")
+		os.Setenv("APP_PORTS", "80,443")
+		defer os.Unsetenv("APP_PORTS")
+
+		if v := Watch[string]("app.version").Load(); v != "1.10" {
+			t.Errorf("Watch[string](app.version).Load() = %q, want %q", v, "1.10")
+		}
+		if v := Watch[string]("app.code").Load(); v != "0123" {
+			t.Errorf("Watch[string](app.code).Load() = %q, want %q", v, "0123")
+		}
+		if v := Watch[float64]("app.version").Load(); v != 1.1 {
+			t.Errorf("Watch[float64](app.version).Load() = %v, want %v", v, 1.1)
+		}
+		if v := Watch[[]int]("app.ports").Load(); !slices.Equal(v, []int{80, 443}) {
+			t.Errorf("Watch[[]int](app.ports).Load() = %v, want [80 443]", v)
+		}
+	})
+
+	t.Run("Close stops updates", func(t *testing.T) {
+		Reset()
+		Set("log.level", "info")
+
+		level := Watch[string]("log.level")
+		calls := 0
+		level.Subscribe(func(string) { calls++ })
+		level.Close()
+		level.Close()
+
+		Set("log.level", "debug")
+		if v := level.Load(); v != "info" {
+			t.Errorf("Load() after Close = %q, want %q", v, "info")
+		}
+		if calls != 0 {
+			t.Errorf("Subscribe callbacks after Close = %d, want 0", calls)
+		}
+		if n := len(std.watchers); n != 0 {
+			t.Errorf("len(watchers) after Close = %d, want 0", n)
+		}
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		values := map[string]any{"timeout": "1s"}
+		cfg, err := New(WithSources(Map("base", values)))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		timeout := WatchConfig[time.Duration](cfg, "timeout")
+
+		values["timeout"] = "2s"
+		if err := cfg.Load(context.Background()); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if v := timeout.Load(); v != 2*time.Second {
+			t.Errorf("Load() = %v, want %v", v, 2*time.Second)
+		}
+	})
+
+	t.Run("concurrent reads", func(t *testing.T) {
+		cfg, err := New(WithSources(Map("base", map[string]any{"n": 0})))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		n := WatchConfig[int](cfg, "n")
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 1; i <= 100; i++ {
+				cfg.Set("n", i)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				_ = n.Load()
+			}
+		}()
+		wg.Wait()
+
+		if v := n.Load(); v != 100 {
+			t.Errorf("Load() = %v, want %v", v, 100)
+		}
+	})
+}
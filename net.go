@@ -0,0 +1,439 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Network getters parse and validate addresses and URLs. Unlike the other
+// getters, they return an error for invalid values instead of a zero value,
+// so that a typo in a deployment fails at startup rather than at the first
+// connection.
+
+// GetURL returns the absolute URL associated with the given key.
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE)
+//  2. Config file value
+//
+// Returns nil and no error if the key is not found, and an error if the
+// value is not an absolute URL (with a scheme).
+//
+// Config file example (config.yaml):
+//
+//	database:
+//	  url: postgres://app@localhost:5432/app?sslmode=disable
+//
+// Environment variable override:
+//
+//	export DATABASE_URL=postgres://app@db.internal:5432/app
+//
+// Usage:
+//
+//	u, err := config.GetURL("database.url")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func GetURL(key string) (*url.URL, error) {
+	return std.GetURL(key)
+}
+
+// GetURL is like [GetURL] but reads from c.
+func (c *Config) GetURL(key string) (*url.URL, error) {
+	return c.Snapshot().GetURL(key)
+}
+
+// GetURL is like [GetURL] but reads from s.
+func (s *Snapshot) GetURL(key string) (*url.URL, error) {
+	val, ok := s.getRaw(key)
+	if !ok {
+		return nil, nil
+	}
+	return parseURL(key, val)
+}
+
+// GetURLs returns the absolute URLs associated with the given key.
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE, comma-separated)
+//  2. Config file value
+//
+// Returns nil and no error if the key is not found, and an error naming the
+// first invalid element otherwise.
+//
+// Config file example (config.yaml):
+//
+//	webhooks:
+//	  targets:
+//	    - https://a.example.com/hook
+//	    - https://b.example.com/hook
+//
+// Environment variable override (comma-separated):
+//
+//	export WEBHOOKS_TARGETS=https://a.example.com/hook,https://b.example.com/hook
+//
+// Usage:
+//
+//	targets, err := config.GetURLs("webhooks.targets")
+func GetURLs(key string) ([]*url.URL, error) {
+	return std.GetURLs(key)
+}
+
+// GetURLs is like [GetURLs] but reads from c.
+func (c *Config) GetURLs(key string) ([]*url.URL, error) {
+	return c.Snapshot().GetURLs(key)
+}
+
+// GetURLs is like [GetURLs] but reads from s.
+func (s *Snapshot) GetURLs(key string) ([]*url.URL, error) {
+	return parseList(s, key, parseURL)
+}
+
+// GetHostPort returns the host:port address associated with the given key,
+// as written. The host may be a name, an IP address or empty (all
+// interfaces); the port must be a number.
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE)
+//  2. Config file value
+//
+// Returns "" and no error if the key is not found, and an error if the value
+// is not a valid address.
+//
+// Config file example (config.yaml):
+//
+//	http:
+//	  listen: :8080
+//	redis:
+//	  addr: localhost:6379
+//
+// Environment variable override:
+//
+//	export HTTP_LISTEN=0.0.0.0:3000
+//
+// Usage:
+//
+//	addr, err := config.GetHostPort("http.listen")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	log.Fatal(http.ListenAndServe(addr, mux))
+func GetHostPort(key string) (string, error) {
+	return std.GetHostPort(key)
+}
+
+// GetHostPort is like [GetHostPort] but reads from c.
+func (c *Config) GetHostPort(key string) (string, error) {
+	return c.Snapshot().GetHostPort(key)
+}
+
+// GetHostPort is like [GetHostPort] but reads from s.
+func (s *Snapshot) GetHostPort(key string) (string, error) {
+	val, ok := s.getRaw(key)
+	if !ok {
+		return "", nil
+	}
+	return parseHostPort(key, val)
+}
+
+// GetHostPorts returns the host:port addresses associated with the given
+// key (see [GetHostPort]).
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE, comma-separated)
+//  2. Config file value
+//
+// Config file example (config.yaml):
+//
+//	kafka:
+//	  brokers:
+//	    - kafka-1:9092
+//	    - kafka-2:9092
+//
+// Environment variable override (comma-separated):
+//
+//	export KAFKA_BROKERS=kafka-1:9092,kafka-2:9092,kafka-3:9092
+//
+// Usage:
+//
+//	brokers, err := config.GetHostPorts("kafka.brokers")
+func GetHostPorts(key string) ([]string, error) {
+	return std.GetHostPorts(key)
+}
+
+// GetHostPorts is like [GetHostPorts] but reads from c.
+func (c *Config) GetHostPorts(key string) ([]string, error) {
+	return c.Snapshot().GetHostPorts(key)
+}
+
+// GetHostPorts is like [GetHostPorts] but reads from s.
+func (s *Snapshot) GetHostPorts(key string) ([]string, error) {
+	return parseList(s, key, parseHostPort)
+}
+
+// GetAddr returns the IP address associated with the given key.
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE)
+//  2. Config file value
+//
+// Returns the zero [netip.Addr] and no error if the key is not found, and an
+// error if the value is not an IPv4 or IPv6 address.
+//
+// Config file example (config.yaml):
+//
+//	dns:
+//	  resolver: 10.0.0.2
+//
+// Environment variable override:
+//
+//	export DNS_RESOLVER=1.1.1.1
+//
+// Usage:
+//
+//	resolver, err := config.GetAddr("dns.resolver")
+func GetAddr(key string) (netip.Addr, error) {
+	return std.GetAddr(key)
+}
+
+// GetAddr is like [GetAddr] but reads from c.
+func (c *Config) GetAddr(key string) (netip.Addr, error) {
+	return c.Snapshot().GetAddr(key)
+}
+
+// GetAddr is like [GetAddr] but reads from s.
+func (s *Snapshot) GetAddr(key string) (netip.Addr, error) {
+	val, ok := s.getRaw(key)
+	if !ok {
+		return netip.Addr{}, nil
+	}
+	return parseAddr(key, val)
+}
+
+// GetAddrs returns the IP addresses associated with the given key.
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE, comma-separated)
+//  2. Config file value
+//
+// Config file example (config.yaml):
+//
+//	dns:
+//	  resolvers:
+//	    - 10.0.0.2
+//	    - fd00::53
+//
+// Environment variable override (comma-separated):
+//
+//	export DNS_RESOLVERS=1.1.1.1,8.8.8.8
+//
+// Usage:
+//
+//	resolvers, err := config.GetAddrs("dns.resolvers")
+func GetAddrs(key string) ([]netip.Addr, error) {
+	return std.GetAddrs(key)
+}
+
+// GetAddrs is like [GetAddrs] but reads from c.
+func (c *Config) GetAddrs(key string) ([]netip.Addr, error) {
+	return c.Snapshot().GetAddrs(key)
+}
+
+// GetAddrs is like [GetAddrs] but reads from s.
+func (s *Snapshot) GetAddrs(key string) ([]netip.Addr, error) {
+	return parseList(s, key, parseAddr)
+}
+
+// GetPrefix returns the IP network (CIDR) associated with the given key. A
+// single address is accepted as a network of one address, e.g. 10.0.0.1 is
+// 10.0.0.1/32.
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE)
+//  2. Config file value
+//
+// Returns the zero [netip.Prefix] and no error if the key is not found, and
+// an error if the value is not a network.
+//
+// Config file example (config.yaml):
+//
+//	admin:
+//	  allowed_network: 10.0.0.0/8
+//
+// Environment variable override:
+//
+//	export ADMIN_ALLOWED_NETWORK=192.168.0.0/16
+//
+// Usage:
+//
+//	network, err := config.GetPrefix("admin.allowed_network")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if !network.Contains(clientIP) {
+//	    http.Error(w, "forbidden", http.StatusForbidden)
+//	}
+func GetPrefix(key string) (netip.Prefix, error) {
+	return std.GetPrefix(key)
+}
+
+// GetPrefix is like [GetPrefix] but reads from c.
+func (c *Config) GetPrefix(key string) (netip.Prefix, error) {
+	return c.Snapshot().GetPrefix(key)
+}
+
+// GetPrefix is like [GetPrefix] but reads from s.
+func (s *Snapshot) GetPrefix(key string) (netip.Prefix, error) {
+	val, ok := s.getRaw(key)
+	if !ok {
+		return netip.Prefix{}, nil
+	}
+	return parsePrefix(key, val)
+}
+
+// GetPrefixes returns the IP networks (CIDRs) associated with the given key
+// (see [GetPrefix]).
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE, comma-separated)
+//  2. Config file value
+//
+// Config file example (config.yaml):
+//
+//	trusted_proxies:
+//	  - 10.0.0.0/8
+//	  - 172.16.0.0/12
+//	  - 192.168.1.10
+//
+// Environment variable override (comma-separated):
+//
+//	export TRUSTED_PROXIES=10.0.0.0/8,fd00::/8
+//
+// Usage:
+//
+//	proxies, err := config.GetPrefixes("trusted_proxies")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func GetPrefixes(key string) ([]netip.Prefix, error) {
+	return std.GetPrefixes(key)
+}
+
+// GetPrefixes is like [GetPrefixes] but reads from c.
+func (c *Config) GetPrefixes(key string) ([]netip.Prefix, error) {
+	return c.Snapshot().GetPrefixes(key)
+}
+
+// GetPrefixes is like [GetPrefixes] but reads from s.
+func (s *Snapshot) GetPrefixes(key string) ([]netip.Prefix, error) {
+	return parseList(s, key, parsePrefix)
+}
+
+// getRaw returns the value of key as a string, from the environment or the
+// config file
+func (s *Snapshot) getRaw(key string) (string, bool) {
+	if val, ok := s.getEnvValue(key); ok {
+		return val, true
+	}
+	if val, ok := s.getFromMap(key); ok {
+		return toString(val), true
+	}
+	return "", false
+}
+
+// parseList parses each element of the list at key with parse. Environment
+// variables are comma-separated.
+func parseList[T any](s *Snapshot, key string, parse func(key, val string) (T, error)) ([]T, error) {
+	var items []string
+	if val, ok := s.getEnvValue(key); ok {
+		items = splitAndTrimStringSlice(val)
+	} else if val, ok := s.getFromMap(key); ok {
+		switch val.(type) {
+		case []any, []string:
+			items = toStringSlice(val)
+		default:
+			return nil, fmt.Errorf("config: %s: expected a list", key)
+		}
+	} else {
+		return nil, nil
+	}
+
+	result := make([]T, len(items))
+	for i, item := range items {
+		v, err := parse(fmt.Sprintf("%s[%d]", key, i), item)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = v
+	}
+	return result, nil
+}
+
+func parseURL(key, val string) (*url.URL, error) {
+	u, err := url.Parse(val)
+	if err != nil {
+		// url.Error repeats the value, which may hold a password
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, invalidValue(key, val, "URL", err.Error())
+	}
+	if u.Scheme == "" {
+		return nil, invalidValue(key, val, "URL", "missing scheme")
+	}
+	return u, nil
+}
+
+func parseHostPort(key, val string) (string, error) {
+	_, port, err := net.SplitHostPort(val)
+	if err != nil {
+		reason := "invalid address"
+		var addrErr *net.AddrError
+		if errors.As(err, &addrErr) {
+			reason = addrErr.Err // without the address, which may hold a secret
+		}
+		return "", invalidValue(key, val, "host:port", reason)
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return "", invalidValue(key, val, "host:port", "invalid port")
+	}
+	return val, nil
+}
+
+func parseAddr(key, val string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(val)
+	if err != nil {
+		return netip.Addr{}, invalidValue(key, val, "IP address", "")
+	}
+	return addr, nil
+}
+
+func parsePrefix(key, val string) (netip.Prefix, error) {
+	if addr, err := netip.ParseAddr(val); err == nil {
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(val)
+	if err != nil {
+		return netip.Prefix{}, invalidValue(key, val, "CIDR", "")
+	}
+	return prefix, nil
+}
+
+// invalidValue returns the error of an invalid value, redacted if key holds
+// a secret or the value has user info, which may hold a password
+func invalidValue(key, val, kind, reason string) error {
+	shown := strconv.Quote(val)
+	if isSecretKey(key) || strings.Contains(val, "@") {
+		shown = redacted
+	}
+	if reason != "" {
+		return fmt.Errorf("config: %s: invalid %s %s: %s", key, kind, shown, reason)
+	}
+	return fmt.Errorf("config: %s: invalid %s %s", key, kind, shown)
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"net/netip"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNetworkGetters(t *testing.T) {
+	t.Run("config file values", func(t *testing.T) {
+		Reset()
+		Set("database.url", "postgres://app@localhost:5432/app?sslmode=disable")
+		Set("http.listen", ":8080")
+		Set("dns.resolver", "10.0.0.2")
+		Set("admin.network", "10.0.0.0/8")
+		Set("trusted_proxies", []any{"10.0.0.0/8", "192.168.1.10", "fd00::/8"})
+		Set("kafka.brokers", []any{"kafka-1:9092", "[::1]:9092"})
+
+		u, err := GetURL("database.url")
+		if err != nil || u.Scheme != "postgres" || u.Host != "localhost:5432" || u.Path != "/app" {
+			t.Errorf("GetURL(database.url) = %v, %v", u, err)
+		}
+		if addr, err := GetHostPort("http.listen"); err != nil || addr != ":8080" {
+			t.Errorf("GetHostPort(http.listen) = %q, %v, want %q", addr, err, ":8080")
+		}
+		if addr, err := GetAddr("dns.resolver"); err != nil || addr != netip.MustParseAddr("10.0.0.2") {
+			t.Errorf("GetAddr(dns.resolver) = %v, %v, want 10.0.0.2", addr, err)
+		}
+		if p, err := GetPrefix("admin.network"); err != nil || p != netip.MustParsePrefix("10.0.0.0/8") {
+			t.Errorf("GetPrefix(admin.network) = %v, %v, want 10.0.0.0/8", p, err)
+		}
+
+		proxies, err := GetPrefixes("trusted_proxies")
+		want := []netip.Prefix{
+			netip.MustParsePrefix("10.0.0.0/8"),
+			netip.MustParsePrefix("192.168.1.10/32"),
+			netip.MustParsePrefix("fd00::/8"),
+		}
+		if err != nil || len(proxies) != len(want) {
+			t.Fatalf("GetPrefixes(trusted_proxies) = %v, %v, want %v", proxies, err, want)
+		}
+		for i := range want {
+			if proxies[i] != want[i] {
+				t.Errorf("GetPrefixes(trusted_proxies)[%d] = %v, want %v", i, proxies[i], want[i])
+			}
+		}
+
+		brokers, err := GetHostPorts("kafka.brokers")
+		if err != nil || len(brokers) != 2 || brokers[1] != "[::1]:9092" {
+			t.Errorf("GetHostPorts(kafka.brokers) = %v, %v", brokers, err)
+		}
+	})
+
+	t.Run("environment variables", func(t *testing.T) {
+		Reset()
+		Set("http.listen", ":8080")
+		Set("trusted_proxies", []any{"10.0.0.0/8"})
+		os.Setenv("HTTP_LISTEN", "0.0.0.0:3000")
+		defer os.Unsetenv("HTTP_LISTEN")
+		os.Setenv("TRUSTED_PROXIES", "172.16.0.0/12, 127.0.0.1,")
+		defer os.Unsetenv("TRUSTED_PROXIES")
+		os.Setenv("DNS_RESOLVERS", "1.1.1.1,8.8.8.8")
+		defer os.Unsetenv("DNS_RESOLVERS")
+		os.Setenv("WEBHOOKS_TARGETS", "https://a.example.com/hook,https://b.example.com/hook")
+		defer os.Unsetenv("WEBHOOKS_TARGETS")
+
+		if addr, err := GetHostPort("http.listen"); err != nil || addr != "0.0.0.0:3000" {
+			t.Errorf("GetHostPort(http.listen) = %q, %v, want %q", addr, err, "0.0.0.0:3000")
+		}
+		proxies, err := GetPrefixes("trusted_proxies")
+		if err != nil || len(proxies) != 2 || proxies[1] != netip.MustParsePrefix("127.0.0.1/32") {
+			t.Errorf("GetPrefixes(trusted_proxies) = %v, %v", proxies, err)
+		}
+		resolvers, err := GetAddrs("dns.resolvers")
+		if err != nil || len(resolvers) != 2 || resolvers[0] != netip.MustParseAddr("1.1.1.1") {
+			t.Errorf("GetAddrs(dns.resolvers) = %v, %v", resolvers, err)
+		}
+		targets, err := GetURLs("webhooks.targets")
+		if err != nil || len(targets) != 2 || targets[1].Host != "b.example.com" {
+			t.Errorf("GetURLs(webhooks.targets) = %v, %v", targets, err)
+		}
+	})
+
+	t.Run("missing keys", func(t *testing.T) {
+		Reset()
+		if u, err := GetURL("database.url"); u != nil || err != nil {
+			t.Errorf("GetURL(database.url) = %v, %v, want nil, nil", u, err)
+		}
+		if addr, err := GetAddr("dns.resolver"); addr.IsValid() || err != nil {
+			t.Errorf("GetAddr(dns.resolver) = %v, %v, want zero, nil", addr, err)
+		}
+		if proxies, err := GetPrefixes("trusted_proxies"); proxies != nil || err != nil {
+			t.Errorf("GetPrefixes(trusted_proxies) = %v, %v, want nil, nil", proxies, err)
+		}
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		Reset()
+		Set("http.listen", "localhost")
+		Set("http.public", "localhost:http")
+		Set("http.admin", "::1:8080")
+		Set("dns.resolver", "10.0.0.256")
+		Set("admin.network", "10.0.0.0/33")
+		Set("database.url", "localhost/app")
+		Set("trusted_proxies", []any{"10.0.0.0/8", "proxy.internal"})
+		Set("kafka.brokers", "kafka-1:9092")
+
+		tests := map[string]func() error{
+			`config: http.listen: invalid host:port "localhost": missing port in address`:  func() error { _, err := GetHostPort("http.listen"); return err },
+			`config: http.admin: invalid host:port "::1:8080": too many colons in address`: func() error { _, err := GetHostPort("http.admin"); return err },
+			`config: http.public: invalid host:port "localhost:http": invalid port`:        func() error { _, err := GetHostPort("http.public"); return err },
+			`config: dns.resolver: invalid IP address "10.0.0.256"`:                        func() error { _, err := GetAddr("dns.resolver"); return err },
+			`config: admin.network: invalid CIDR "10.0.0.0/33"`:                            func() error { _, err := GetPrefix("admin.network"); return err },
+			`config: database.url: invalid URL "localhost/app": missing scheme`:            func() error { _, err := GetURL("database.url"); return err },
+			`config: trusted_proxies[1]: invalid CIDR "proxy.internal"`:                    func() error { _, err := GetPrefixes("trusted_proxies"); return err },
+			`config: kafka.brokers: expected a list`:                                       func() error { _, err := GetHostPorts("kafka.brokers"); return err },
+		}
+		for want, get := range tests {
+			if err := get(); err == nil || err.Error() != want {
+				t.Errorf("error = %v, want %q", err, want)
+			}
+		}
+	})
+
+	t.Run("passwords are not shown in errors", func(t *testing.T) {
+		Reset()
+		Set("database.url", "postgres://app:hunter2@db:port/app")
+		Set("database.password", "hunter2")
+
+		_, err := GetURL("database.url")
+		if err == nil || strings.Contains(err.Error(), "hunter2") {
+			t.Errorf("GetURL(database.url) error = %v, want a redacted error", err)
+		}
+		_, err = GetAddr("database.password")
+		if err == nil || strings.Contains(err.Error(), "hunter2") {
+			t.Errorf("GetAddr(database.password) error = %v, want a redacted error", err)
+		}
+	})
+
+	t.Run("snapshot", func(t *testing.T) {
+		Reset()
+		Set("http.listen", ":8080")
+		snap := GetSnapshot()
+		Set("http.listen", ":9090")
+
+		if addr, err := snap.GetHostPort("http.listen"); err != nil || addr != ":8080" {
+			t.Errorf("snapshot GetHostPort(http.listen) = %q, %v, want %q", addr, err, ":8080")
+		}
+	})
+}
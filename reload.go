@@ -0,0 +1,143 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ReloadResult describes the outcome of a reload (see [Reload]).
+type ReloadResult struct {
+	// Time is when the reload finished.
+	Time time.Time
+	// Err is the reason the reload failed, or nil if it succeeded. After a
+	// failure, the previous configuration stays in effect.
+	Err error
+	// Changed lists the keys that were added, removed or modified, sorted
+	// (see [Diff]).
+	Changed []string
+}
+
+var (
+	reloadMu   sync.Mutex // serializes reloads
+	lastReload ReloadResult
+)
+
+// AddValidator registers a function that checks the configuration every time
+// it is loaded, before it takes effect. If a validator returns an error, [Init]
+// fails and [Reload] keeps the previous configuration. The errors of all
+// validators are reported together.
+//
+// Usage:
+//
+//	config.AddValidator(func(s *config.Snapshot) error {
+//	    if s.GetInt("http.port") == 0 {
+//	        return errors.New("http.port is required")
+//	    }
+//	    return nil
+//	})
+//	config.Init()
+func AddValidator(fn func(s *Snapshot) error) {
+	std.AddValidator(fn)
+}
+
+// AddValidator is like [AddValidator] but validates the loads of c.
+func (c *Config) AddValidator(fn func(s *Snapshot) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.validators = append(c.validators, fn)
+}
+
+// Reload runs the loading pipeline of [Init] again: config.yaml discovery,
+// .env, config fragments, decryption, secret references and validators (see
+// [AddValidator]).
+//
+// The new configuration replaces the current one only if every step succeeds;
+// otherwise the last known good configuration stays in effect and the
+// watchers (see [Watch]) are not notified. Errors, including a config.yaml
+// that cannot be searched for, are returned rather than exiting. The outcome is
+// logged (see [SetLogger]) and returned by [LastReload].
+//
+// Usage:
+//
+//	if err := config.Reload(ctx); err != nil {
+//	    log.Printf("keeping previous config: %s", err)
+//	}
+func Reload(ctx context.Context) error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	before := std.Snapshot()
+	err := initDefault(ctx)
+
+	result := ReloadResult{Time: time.Now(), Err: err}
+	if err == nil {
+		for _, change := range Diff(before, std.Snapshot()) {
+			result.Changed = append(result.Changed, change.Key)
+		}
+	}
+	lastReload = result
+
+	aliasMu.RLock()
+	defer aliasMu.RUnlock()
+	if logger != nil {
+		if err != nil {
+			logger.Printf("config: reload failed, keeping previous configuration: %s", err)
+		} else {
+			logger.Printf("config: reloaded, %d keys changed", len(result.Changed))
+		}
+	}
+	return err
+}
+
+// ReloadOnSignal calls [Reload] every time the process receives one of the
+// given signals, until ctx is done. It returns immediately.
+//
+// Usage:
+//
+//	config.Init()
+//	config.ReloadOnSignal(ctx, syscall.SIGHUP)  // kill -HUP <pid> reloads config.yaml
+func ReloadOnSignal(ctx context.Context, sigs ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				_ = Reload(ctx)
+			}
+		}
+	}()
+}
+
+// LastReload returns the outcome of the last [Reload], or the zero value if
+// the configuration has not been reloaded.
+//
+// Usage:
+//
+//	http.HandleFunc("/debug/config", func(w http.ResponseWriter, r *http.Request) {
+//	    status := config.LastReload()
+//	    fmt.Fprintf(w, "last reload: %s, error: %v, changed: %v\n", status.Time, status.Err, status.Changed)
+//	})
+func LastReload() ReloadResult {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	result := lastReload
+	result.Changed = slices.Clone(result.Changed)
+	return result
+}
+
+// resetReloads forgets the outcome of the last reload.
+func resetReloads() {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	lastReload = ReloadResult{}
+}
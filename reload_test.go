@@ -0,0 +1,113 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "config.yaml", "http:\n  port: 8080\n  host: localhost\nname: app\n")
+
+	originalDir, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	t.Run("applies changes", func(t *testing.T) {
+		Reset()
+		Init()
+		writeTestFile(t, dir, "config.yaml", "http:\n  port: 9090\n  host: localhost\nlevel: debug\n")
+
+		if err := Reload(context.Background()); err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+		if got := GetInt("http.port"); got != 9090 {
+			t.Errorf("GetInt(http.port) = %d, want %d", got, 9090)
+		}
+
+		result := LastReload()
+		if result.Err != nil || result.Time.IsZero() {
+			t.Errorf("LastReload() = %+v, want a successful reload", result)
+		}
+		want := []string{"http.port", "level", "name"}
+		if !reflect.DeepEqual(result.Changed, want) {
+			t.Errorf("LastReload().Changed = %v, want %v", result.Changed, want)
+		}
+	})
+
+	t.Run("keeps the previous configuration on error", func(t *testing.T) {
+		Reset()
+		writeTestFile(t, dir, "config.yaml", "http:\n  port: 8080\n")
+		Init()
+		logger := &recordingLogger{}
+		SetLogger(logger)
+		defer SetLogger(nil)
+
+		notified := 0
+		std.watchMu.Lock()
+		std.watchers = append(std.watchers, &watcher{fn: func() { notified++ }})
+		std.watchMu.Unlock()
+		before := GetSnapshot()
+
+		writeTestFile(t, dir, "config.yaml", "http: [unclosed\n")
+		if err := Reload(context.Background()); err == nil {
+			t.Fatal("Reload() error = nil, want error")
+		}
+		if got := GetInt("http.port"); got != 8080 {
+			t.Errorf("GetInt(http.port) = %d, want %d", got, 8080)
+		}
+		if GetSnapshot() != before || notified != 0 {
+			t.Errorf("failed Reload() published a snapshot and notified %d watchers", notified)
+		}
+		if result := LastReload(); result.Err == nil || result.Changed != nil {
+			t.Errorf("LastReload() = %+v, want a failed reload", result)
+		}
+		if len(logger.messages) != 1 {
+			t.Errorf("logged %v, want one message", logger.messages)
+		}
+	})
+
+	t.Run("validators reject invalid configuration", func(t *testing.T) {
+		Reset()
+		writeTestFile(t, dir, "config.yaml", "http:\n  port: 8080\n")
+		AddValidator(func(s *Snapshot) error {
+			if s.GetInt("http.port") == 0 {
+				return errors.New("http.port is required")
+			}
+			return nil
+		})
+		Init()
+
+		writeTestFile(t, dir, "config.yaml", "http:\n  host: localhost\n")
+		err := Reload(context.Background())
+		if err == nil || err.Error() != "config: invalid configuration: http.port is required" {
+			t.Fatalf("Reload() error = %v, want validation error", err)
+		}
+		if got := GetInt("http.port"); got != 8080 {
+			t.Errorf("GetInt(http.port) = %d, want %d", got, 8080)
+		}
+		if IsSet("http.host") {
+			t.Error("IsSet(http.host) = true, want false")
+		}
+	})
+
+	t.Run("Reset clears validators and the last reload", func(t *testing.T) {
+		Reset()
+		AddValidator(func(s *Snapshot) error { return errors.New("invalid") })
+		_ = Reload(context.Background())
+		Reset()
+
+		if err := Reload(context.Background()); err != nil {
+			t.Errorf("Reload() error = %v, want nil", err)
+		}
+		Reset()
+		if result := LastReload(); !result.Time.IsZero() {
+			t.Errorf("LastReload() = %+v, want zero value", result)
+		}
+	})
+}
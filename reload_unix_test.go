@@ -0,0 +1,45 @@
+//go:build unix
+
+package config
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReloadOnSignal(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "config.yaml", "http:\n  port: 8080\n")
+
+	originalDir, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	Reset()
+	Init()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ReloadOnSignal(ctx, syscall.SIGHUP)
+
+	writeTestFile(t, dir, "config.yaml", "http:\n  port: 9090\n")
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("Kill() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for LastReload().Time.IsZero() {
+		if time.Now().After(deadline) {
+			t.Fatal("configuration was not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := GetInt("http.port"); got != 9090 {
+		t.Errorf("GetInt(http.port) = %d, want %d", got, 9090)
+	}
+}
@@ -0,0 +1,459 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultResolveTimeout is the time a single secret reference may take to
+// resolve, unless overridden with [WithTimeout].
+const DefaultResolveTimeout = 10 * time.Second
+
+// SecretResolver resolves secret references found in config values.
+//
+// A reference is a URL whose scheme is the name the resolver was registered
+// with (see [RegisterResolver]), e.g. vault://db#password. The generic form
+// secretref://<name>/<path> is accepted as well and passed to the resolver as
+// <name>://<path>.
+//
+// Resolve must honor ctx, which carries the resolve timeout.
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref *url.URL) (string, error)
+}
+
+// ResolverFunc adapts an ordinary function to the [SecretResolver] interface.
+type ResolverFunc func(ctx context.Context, ref *url.URL) (string, error)
+
+// Resolve calls f(ctx, ref).
+func (f ResolverFunc) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	return f(ctx, ref)
+}
+
+// ResolverOption configures a registered [SecretResolver].
+type ResolverOption func(*registeredResolver)
+
+// WithTTL sets how long resolved values are cached. Cached values older than
+// ttl are resolved again by [RefreshSecrets]. The default, 0, caches values
+// until the configuration is loaded again.
+func WithTTL(ttl time.Duration) ResolverOption {
+	return func(r *registeredResolver) {
+		r.ttl = ttl
+	}
+}
+
+// WithTimeout sets the time a single reference may take to resolve.
+// The default is [DefaultResolveTimeout].
+func WithTimeout(timeout time.Duration) ResolverOption {
+	return func(r *registeredResolver) {
+		r.timeout = timeout
+	}
+}
+
+type registeredResolver struct {
+	resolver SecretResolver
+	ttl      time.Duration
+	timeout  time.Duration
+}
+
+type cachedSecret struct {
+	value    string
+	resolved time.Time
+}
+
+// secretCache holds the resolved values of the secret references of a
+// Config, by cache key (see refScope.cacheKey). It is guarded by Config.mu and
+// replaced on every Load.
+type secretCache map[string]cachedSecret
+
+var (
+	resolverMu sync.RWMutex
+	resolvers  = map[string]*registeredResolver{}
+
+	// builtinResolvers are only used through secretref:// references, so that
+	// ordinary values such as file:///tmp/app.db are not taken for secrets
+	builtinResolvers = map[string]*registeredResolver{
+		"file": {resolver: ResolverFunc(resolveFile), timeout: DefaultResolveTimeout},
+		"env":  {resolver: ResolverFunc(resolveEnv), timeout: DefaultResolveTimeout},
+	}
+)
+
+// RegisterResolver registers a [SecretResolver] for the given URL scheme.
+// Registering a scheme again replaces the previous resolver.
+//
+// No scheme is registered by default. The following resolvers are built in,
+// and available through secretref:// references only:
+//   - secretref://file/secrets/db_password reads a file relative to the
+//     directory of the config file (secretref://file//run/secrets/db for an
+//     absolute path), trimming the trailing newline
+//   - secretref://env/DB_PASSWORD reads an environment variable of the
+//     configuration's environment layers
+//
+// Only values whose scheme is registered are resolved, so regular URLs such as
+// https://example.com or file:///tmp/app.db are left untouched.
+//
+// Usage:
+//
+//	config.RegisterResolver("vault", vaultResolver, config.WithTTL(5*time.Minute))
+//	config.Init()
+//
+// Config file example (config.yaml):
+//
+//	database:
+//	  password: secretref://vault/db#password
+func RegisterResolver(scheme string, r SecretResolver, opts ...ResolverOption) {
+	reg := &registeredResolver{resolver: r, timeout: DefaultResolveTimeout}
+	for _, opt := range opts {
+		opt(reg)
+	}
+
+	resolverMu.Lock()
+	defer resolverMu.Unlock()
+	resolvers[strings.ToLower(scheme)] = reg
+}
+
+// RefreshSecrets resolves secret references added since the configuration was
+// loaded (e.g., via [Set]) and resolves again every reference whose cached
+// value is older than the TTL of its resolver (see [WithTTL]). Values that
+// fail to resolve keep their previous value.
+//
+// Usage:
+//
+//	go func() {
+//	    for range time.Tick(time.Minute) {
+//	        if err := config.RefreshSecrets(ctx); err != nil {
+//	            log.Printf("refresh secrets: %s", err)
+//	        }
+//	    }
+//	}()
+func RefreshSecrets(ctx context.Context) error {
+	return std.RefreshSecrets(ctx)
+}
+
+// RefreshSecrets is like [RefreshSecrets] but refreshes the secrets of c.
+func (c *Config) RefreshSecrets(ctx context.Context) error {
+	var errs []error
+
+	c.mu.Lock()
+	defer c.notify() // after c.mu is released
+	defer c.mu.Unlock()
+
+	if c.secrets == nil {
+		c.secrets = make(secretCache)
+	}
+	next := c.snap.Load().clone()
+	for _, l := range next.layers {
+		if l.kind == envLayer {
+			continue
+		}
+
+		// Resolve references added since the layer was loaded
+		data := sectionCopy(l.data, "")
+		found := make(map[string]string)
+		ctx := withRefScope(ctx, c.secrets, next, l)
+		if err := resolveSecretRefs(ctx, data, "", found); err != nil {
+			errs = append(errs, err)
+		}
+
+		refs := maps.Clone(l.refs)
+		if refs == nil {
+			refs = make(map[string]string)
+		}
+		maps.Copy(refs, found)
+
+		for _, key := range slices.Sorted(maps.Keys(refs)) {
+			value, err := resolveRef(ctx, refs[key], true)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%w (%s)", err, key))
+				continue
+			}
+			setRef(data, key, Secret(value))
+		}
+		l.data = data
+		l.refs = refs
+	}
+	c.snap.Store(next)
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("config: refresh secrets: %w", err)
+	}
+	return nil
+}
+
+// resolveSecretRefs replaces every secret reference in data, the (sub)tree
+// rooted at prefix, with its resolved value as a [Secret] and records the
+// reference in refs. Items of lists are keyed by index, e.g. tokens.0.
+func resolveSecretRefs(ctx context.Context, data map[string]any, prefix string, refs map[string]string) error {
+	var errs []error
+	for _, k := range slices.Sorted(maps.Keys(data)) {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		val, err := resolveSecretRefValue(ctx, data[k], key, refs)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		data[k] = val
+	}
+	return errors.Join(errs...)
+}
+
+// resolveSecretRefValue is like resolveSecretRefs for v, the value of key
+func resolveSecretRefValue(ctx context.Context, v any, key string, refs map[string]string) (any, error) {
+	switch val := v.(type) {
+	case map[string]any:
+		return val, resolveSecretRefs(ctx, val, key, refs)
+	case []any:
+		var errs []error
+		for i, item := range val {
+			item, err := resolveSecretRefValue(ctx, item, key+"."+strconv.Itoa(i), refs)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			val[i] = item
+		}
+		return val, errors.Join(errs...)
+	case string:
+		if _, _, ok := parseSecretRef(val); !ok {
+			return val, nil
+		}
+		value, err := resolveRef(ctx, val, false)
+		if err != nil {
+			return val, fmt.Errorf("%w (%s)", err, key)
+		}
+		refs[key] = val
+		return Secret(value), nil
+	}
+	return v, nil
+}
+
+// setRef sets the value of key in data like setPath, except that the parts
+// of key may be indexes of lists (see resolveSecretRefs)
+func setRef(data map[string]any, key string, value any) {
+	parts := strings.Split(key, ".")
+	var current any = data
+	for i, part := range parts {
+		last := i == len(parts)-1
+		switch node := current.(type) {
+		case map[string]any:
+			if last {
+				node[part] = value
+				return
+			}
+			if _, ok := node[part]; !ok {
+				node[part] = make(map[string]any)
+			}
+			current = node[part]
+		case []any:
+			n, err := strconv.Atoi(part)
+			if err != nil || n < 0 || n >= len(node) {
+				return
+			}
+			if last {
+				node[n] = value
+				return
+			}
+			current = node[n]
+		default:
+			return
+		}
+	}
+}
+
+// resolveRef resolves a secret reference, using the cache of the scope of
+// ctx (see withRefScope) unless the cached value has expired. If refresh is
+// false, cached values never expire.
+func resolveRef(ctx context.Context, ref string, refresh bool) (string, error) {
+	reg, u, ok := parseSecretRef(ref)
+	if !ok {
+		return "", fmt.Errorf("no resolver registered for %q", ref)
+	}
+
+	scope, _ := ctx.Value(refScopeKey{}).(refScope)
+	key := scope.cacheKey(reg, u)
+	cached, ok := scope.cache[key]
+	if ok && (!refresh || reg.ttl <= 0 || time.Since(cached.resolved) < reg.ttl) {
+		return cached.value, nil
+	}
+
+	if reg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, reg.timeout)
+		defer cancel()
+	}
+	value, err := reg.resolver.Resolve(ctx, u)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve %s: %w", ref, err)
+	}
+
+	if scope.cache != nil {
+		scope.cache[key] = cachedSecret{value: value, resolved: time.Now()}
+	}
+	return value, nil
+}
+
+// parseSecretRef returns the resolver and URL of a secret reference, or false
+// if s is not a reference to a registered resolver.
+func parseSecretRef(s string) (*registeredResolver, *url.URL, bool) {
+	scheme, rest, ok := strings.Cut(s, "://")
+	if !ok || scheme == "" {
+		return nil, nil, false
+	}
+	scheme = strings.ToLower(scheme)
+
+	// secretref://<name>/<path> is the same as <name>://<path>
+	explicit := scheme == "secretref"
+	if explicit {
+		name, path, _ := strings.Cut(rest, "/")
+		scheme, rest = strings.ToLower(name), path
+	}
+
+	resolverMu.RLock()
+	reg, ok := resolvers[scheme]
+	resolverMu.RUnlock()
+	if !ok && explicit {
+		reg, ok = builtinResolvers[scheme]
+	}
+	if !ok {
+		return nil, nil, false
+	}
+
+	u, err := url.Parse(scheme + "://" + rest)
+	if err != nil {
+		return nil, nil, false
+	}
+	return reg, u, true
+}
+
+// refScope is what the built-in resolvers resolve references against
+type refScope struct {
+	dir    string                      // directory of relative file paths
+	lookup func(string) (string, bool) // environment variables
+	cache  secretCache                 // of the Config, nil to disable caching
+}
+
+type refScopeKey struct{}
+
+// withRefScope returns a copy of ctx for resolving the references of l, a
+// layer of s, with cache: relative files are read from the directory of its
+// config file and environment variables from the environment layers of s
+func withRefScope(ctx context.Context, cache secretCache, s *Snapshot, l *layer) context.Context {
+	scope := refScope{cache: cache, lookup: s.lookupEnv}
+	if src, ok := l.source.(*fileSource); ok {
+		scope.dir = filepath.Dir(src.path)
+	}
+	return context.WithValue(ctx, refScopeKey{}, scope)
+}
+
+// cacheKey returns the key of the value of ref in the cache: the path of the
+// file read by the built-in file resolver, since relative paths depend on the
+// config file, and the reference otherwise
+func (scope refScope) cacheKey(reg *registeredResolver, ref *url.URL) string {
+	if reg == builtinResolvers["file"] {
+		return "file://" + scope.filePath(ref)
+	}
+	return ref.String()
+}
+
+// filePath returns the path of the file of a secretref://file reference
+func (scope refScope) filePath(ref *url.URL) string {
+	path := ref.Host + ref.Path
+	if !filepath.IsAbs(path) && scope.dir != "" {
+		path = filepath.Join(scope.dir, path)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return path
+}
+
+// resolveFile resolves secretref://file/relative/path and
+// secretref://file//absolute/path references.
+func resolveFile(ctx context.Context, ref *url.URL) (string, error) {
+	scope, _ := ctx.Value(refScopeKey{}).(refScope)
+	data, err := os.ReadFile(scope.filePath(ref))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// resolveEnv resolves secretref://env/NAME references.
+func resolveEnv(ctx context.Context, ref *url.URL) (string, error) {
+	lookup := os.LookupEnv
+	if scope, ok := ctx.Value(refScopeKey{}).(refScope); ok {
+		lookup = scope.lookup
+	}
+	value, ok := lookup(ref.Host)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", ref.Host)
+	}
+	return value, nil
+}
+
+// MemoryResolver is an in-memory [SecretResolver], intended for tests.
+//
+// References are looked up by everything after the scheme, e.g. the value of
+// memory://db/password#user is looked up as "db/password#user".
+//
+// Usage:
+//
+//	r := config.NewMemoryResolver(map[string]string{"db#password": "secret123"})
+//	config.RegisterResolver("vault", r)
+//	config.Set("database.password", "secretref://vault/db#password")
+type MemoryResolver struct {
+	mu      sync.RWMutex
+	secrets map[string]string
+	calls   int
+}
+
+// NewMemoryResolver returns a [MemoryResolver] holding a copy of secrets.
+func NewMemoryResolver(secrets map[string]string) *MemoryResolver {
+	r := &MemoryResolver{secrets: make(map[string]string, len(secrets))}
+	for ref, value := range secrets {
+		r.secrets[ref] = value
+	}
+	return r
+}
+
+// Set stores the value of a reference.
+func (r *MemoryResolver) Set(ref, value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.secrets[ref] = value
+}
+
+// Calls returns the number of times Resolve has been called.
+func (r *MemoryResolver) Calls() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.calls
+}
+
+// Resolve implements [SecretResolver].
+func (r *MemoryResolver) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+
+	name := strings.TrimPrefix(ref.String(), ref.Scheme+"://")
+	value, ok := r.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("secret %q not found", name)
+	}
+	return value, nil
+}
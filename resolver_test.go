@@ -0,0 +1,283 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRefreshSecrets(t *testing.T) {
+	t.Run("file and env resolvers", func(t *testing.T) {
+		Reset()
+		path := filepath.Join(t.TempDir(), "db_password")
+		if err := os.WriteFile(path, []byte("from_file\n"), 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		os.Setenv("TEST_RESOLVER_TOKEN", "from_env")
+		defer os.Unsetenv("TEST_RESOLVER_TOKEN")
+
+		Set("database.pass", "secretref://file/"+path)
+		Set("api.key", "secretref://env/TEST_RESOLVER_TOKEN")
+		Set("api.url", "https://example.com")
+		Set("database.url", "file:///tmp/app.db")
+
+		if err := RefreshSecrets(context.Background()); err != nil {
+			t.Fatalf("RefreshSecrets returned error: %v", err)
+		}
+		if got := GetString("database.pass"); got != "from_file" {
+			t.Errorf("GetString(database.pass) = %q, want %q", got, "from_file")
+		}
+		if got := GetString("api.key"); got != "from_env" {
+			t.Errorf("GetString(api.key) = %q, want %q", got, "from_env")
+		}
+		if got := GetString("api.url"); got != "https://example.com" {
+			t.Errorf("GetString(api.url) = %q, want %q", got, "https://example.com")
+		}
+		if got := GetString("database.url"); got != "file:///tmp/app.db" {
+			t.Errorf("GetString(database.url) = %q, want %q", got, "file:///tmp/app.db")
+		}
+
+		settings := AllSettings()
+		if got := settings["database"].(map[string]any)["pass"]; got != Secret("from_file") {
+			t.Errorf("AllSettings()[database][pass] = %#v, want redacted", got)
+		}
+	})
+
+	t.Run("missing env var", func(t *testing.T) {
+		Reset()
+		Set("api.key", "secretref://env/TEST_RESOLVER_MISSING")
+
+		err := RefreshSecrets(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "api.key") || strings.Count(err.Error(), "config:") != 1 {
+			t.Errorf("RefreshSecrets() error = %v, want one error naming api.key", err)
+		}
+		if got := GetString("api.key"); got != "secretref://env/TEST_RESOLVER_MISSING" {
+			t.Errorf("GetString(api.key) = %q, want unchanged reference", got)
+		}
+	})
+
+	t.Run("references from a config file", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.Mkdir(filepath.Join(dir, "secrets"), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "secrets", "db_password"), []byte("from_file\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		yaml := "database:\n  url: file:///tmp/app.db\n  password: secretref://file/secrets/db_password\n" +
+			"api:\n  token: secretref://env/API_TOKEN\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		// Relative to the config file, not to the working directory
+		c, err := New(WithSources(File(filepath.Join(dir, "config.yaml")), EnvMap(map[string]string{"API_TOKEN": "from_env"})))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if got := c.GetString("database.password"); got != "from_file" {
+			t.Errorf("GetString(database.password) = %q, want %q", got, "from_file")
+		}
+		if got := c.GetString("database.url"); got != "file:///tmp/app.db" {
+			t.Errorf("GetString(database.url) = %q, want %q", got, "file:///tmp/app.db")
+		}
+		// From the environment layer of c, not from the process environment
+		if got := c.GetString("api.token"); got != "from_env" {
+			t.Errorf("GetString(api.token) = %q, want %q", got, "from_env")
+		}
+	})
+
+	t.Run("each Config resolves its own references", func(t *testing.T) {
+		configs := make([]*Config, 2)
+		for i, value := range []string{"AAA", "BBB"} {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "secret"), []byte(value), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			yaml := "database:\n  password: secretref://file/secret\napi:\n  token: secretref://env/API_TOKEN\n"
+			if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			c, err := New(WithSources(File(filepath.Join(dir, "config.yaml")), EnvMap(map[string]string{"API_TOKEN": value})))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			configs[i] = c
+		}
+
+		for i, want := range []string{"AAA", "BBB"} {
+			if got := configs[i].GetString("database.password"); got != want {
+				t.Errorf("configs[%d].GetString(database.password) = %q, want %q", i, got, want)
+			}
+			if got := configs[i].GetString("api.token"); got != want {
+				t.Errorf("configs[%d].GetString(api.token) = %q, want %q", i, got, want)
+			}
+		}
+	})
+
+	t.Run("Load resolves references again", func(t *testing.T) {
+		r := NewMemoryResolver(map[string]string{"db#password": "v1"})
+		RegisterResolver("testvault", r)
+
+		c, err := New(WithSources(Map("app", map[string]any{"database.password": "secretref://testvault/db#password"})))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		r.Set("db#password", "v2")
+		if err := c.Load(context.Background()); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if got := c.GetString("database.password"); got != "v2" {
+			t.Errorf("GetString(database.password) = %q, want %q", got, "v2")
+		}
+	})
+
+	t.Run("references in lists", func(t *testing.T) {
+		Reset()
+		r := NewMemoryResolver(map[string]string{"a": "token-a", "b": "token-b"})
+		RegisterResolver("testvault", r, WithTTL(time.Hour))
+		Set("api.tokens", []any{"secretref://testvault/a", "plain"})
+		Set("upstreams", []any{map[string]any{"password": "secretref://testvault/b"}})
+
+		if err := RefreshSecrets(context.Background()); err != nil {
+			t.Fatalf("RefreshSecrets returned error: %v", err)
+		}
+		if got := GetStringSlice("api.tokens"); len(got) != 2 || got[0] != "token-a" || got[1] != "plain" {
+			t.Errorf("GetStringSlice(api.tokens) = %q, want [token-a plain]", got)
+		}
+
+		// Refreshed in place
+		r.Set("b", "token-b2")
+		std.mu.Lock()
+		for ref, cached := range std.secrets {
+			cached.resolved = cached.resolved.Add(-2 * time.Hour)
+			std.secrets[ref] = cached
+		}
+		std.mu.Unlock()
+		if err := RefreshSecrets(context.Background()); err != nil {
+			t.Fatalf("RefreshSecrets returned error: %v", err)
+		}
+		upstreams, err := GetSlice[struct {
+			Password string `yaml:"password"`
+		}]("upstreams")
+		if err != nil || len(upstreams) != 1 || upstreams[0].Password != "token-b2" {
+			t.Errorf("GetSlice(upstreams) = %+v, %v, want the refreshed password", upstreams, err)
+		}
+	})
+
+	t.Run("secretref with TTL", func(t *testing.T) {
+		Reset()
+		r := NewMemoryResolver(map[string]string{"db#password": "v1"})
+		RegisterResolver("testvault", r, WithTTL(time.Hour))
+		Set("database.password", "secretref://testvault/db#password")
+
+		if err := RefreshSecrets(context.Background()); err != nil {
+			t.Fatalf("RefreshSecrets returned error: %v", err)
+		}
+		if got := GetString("database.password"); got != "v1" {
+			t.Errorf("GetString(database.password) = %q, want %q", got, "v1")
+		}
+
+		// Cached until the TTL expires.
+		r.Set("db#password", "v2")
+		if err := RefreshSecrets(context.Background()); err != nil {
+			t.Fatalf("RefreshSecrets returned error: %v", err)
+		}
+		if got := GetString("database.password"); got != "v1" || r.Calls() != 1 {
+			t.Errorf("GetString(database.password) = %q after %d calls, want cached %q", got, r.Calls(), "v1")
+		}
+
+		std.mu.Lock()
+		for ref, cached := range std.secrets {
+			cached.resolved = cached.resolved.Add(-2 * time.Hour)
+			std.secrets[ref] = cached
+		}
+		std.mu.Unlock()
+
+		if err := RefreshSecrets(context.Background()); err != nil {
+			t.Fatalf("RefreshSecrets returned error: %v", err)
+		}
+		if got := GetString("database.password"); got != "v2" {
+			t.Errorf("GetString(database.password) = %q, want %q", got, "v2")
+		}
+	})
+
+	t.Run("HTTP resolver timeout", func(t *testing.T) {
+		Reset()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow" {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(5 * time.Second):
+				}
+				return
+			}
+			io.WriteString(w, "secret-for-"+strings.TrimPrefix(r.URL.Path, "/"))
+		}))
+		defer server.Close()
+
+		RegisterResolver("testhttp", ResolverFunc(func(ctx context.Context, ref *url.URL) (string, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/"+ref.Host, nil)
+			if err != nil {
+				return "", err
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return "", err
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			return string(body), err
+		}), WithTimeout(50*time.Millisecond))
+
+		Set("api.token", "testhttp://stripe")
+		if err := RefreshSecrets(context.Background()); err != nil {
+			t.Fatalf("RefreshSecrets returned error: %v", err)
+		}
+		if got := GetString("api.token"); got != "secret-for-stripe" {
+			t.Errorf("GetString(api.token) = %q, want %q", got, "secret-for-stripe")
+		}
+
+		Set("api.slow", "testhttp://slow")
+		err := RefreshSecrets(context.Background())
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("RefreshSecrets() error = %v, want deadline exceeded", err)
+		}
+	})
+}
+
+func TestParseSecretRef(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{"secretref://file//run/secrets/db", "file:///run/secrets/db", true},
+		{"secretref://env/DB_PASSWORD", "env://DB_PASSWORD", true},
+		{"file:///run/secrets/db", "", false},
+		{"env://DB_PASSWORD", "", false},
+		{"https://example.com", "", false},
+		{"secretref://unknown/db", "", false},
+		{"plain value", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, u, ok := parseSecretRef(tt.input)
+			if ok != tt.ok {
+				t.Fatalf("parseSecretRef(%q) ok = %v, want %v", tt.input, ok, tt.ok)
+			}
+			if ok && u.String() != tt.want {
+				t.Errorf("parseSecretRef(%q) = %q, want %q", tt.input, u.String(), tt.want)
+			}
+		})
+	}
+}
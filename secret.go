@@ -0,0 +1,232 @@
+package config
+
+import (
+	"encoding/json"
+	"log/slog"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// redacted is printed in place of secret values.
+const redacted = "[REDACTED]"
+
+// Secret is a string that is redacted whenever it is printed, logged or
+// marshaled, so that passwords and API keys do not leak into logs.
+//
+// Use [Secret.Reveal] to obtain the actual value.
+//
+// Usage:
+//
+//	password := config.GetSecret("database.password")
+//	log.Printf("connecting with %v", password)  // connecting with [REDACTED]
+//	db.Connect(password.Reveal())
+//
+// Struct fields of type Secret are redacted in [AllSettings] as well:
+//
+//	type DatabaseConfig struct {
+//	    Password config.Secret `yaml:"password"`
+//	}
+type Secret string
+
+// Reveal returns the actual secret value.
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+// String implements [fmt.Stringer].
+func (s Secret) String() string {
+	return redacted
+}
+
+// GoString implements [fmt.GoStringer].
+func (s Secret) GoString() string {
+	return redacted
+}
+
+// MarshalJSON implements [json.Marshaler].
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+// MarshalYAML implements [yaml.Marshaler].
+func (s Secret) MarshalYAML() (any, error) {
+	return redacted, nil
+}
+
+// LogValue implements [slog.LogValuer].
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// defaultSecretPatterns are the key patterns redacted by default.
+var defaultSecretPatterns = []string{
+	"*password*",
+	"*passwd*",
+	"*secret*",
+	"*token*",
+	"*api_key*",
+	"*apikey*",
+	"*private_key*",
+	"*credential*",
+}
+
+var (
+	secretMu       sync.RWMutex
+	secretPatterns = slices.Clone(defaultSecretPatterns)
+)
+
+// RedactKeys adds key patterns whose values are redacted by [AllSettings].
+//
+// Patterns use [path.Match] syntax and are matched case-insensitively against
+// the full dot notation key. Exact keys are valid patterns too. The following
+// patterns are always redacted: *password*, *passwd*, *secret*, *token*,
+// *api_key*, *apikey*, *private_key* and *credential*.
+//
+// Struct fields tagged with `secret:"true"` or of type [Secret] are added
+// automatically when the struct is passed to [Unmarshal] or [UnmarshalKey].
+//
+// Usage:
+//
+//	config.RedactKeys("*dsn*", "stripe.webhook_signing")
+func RedactKeys(patterns ...string) {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if !slices.Contains(secretPatterns, pattern) {
+			secretPatterns = append(secretPatterns, pattern)
+		}
+	}
+}
+
+// IsRedacted reports whether the value of key is redacted by [AllSettings],
+// because key matches one of the patterns of [RedactKeys] or its value is a
+// [Secret], e.g. a decrypted value.
+//
+// Usage:
+//
+//	config.IsRedacted("database.password")  // true
+func IsRedacted(key string) bool {
+	if isSecretKey(key) {
+		return true
+	}
+	val, _, _ := std.Snapshot().find(key, true)
+	_, secret := val.(Secret)
+	return secret
+}
+
+// isSecretKey reports whether the value of key must be redacted.
+func isSecretKey(key string) bool {
+	secretMu.RLock()
+	defer secretMu.RUnlock()
+
+	key = strings.ToLower(key)
+	for _, pattern := range secretPatterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// registerStructSecrets redacts the keys of struct fields tagged with
+// `secret:"true"` or of type [Secret].
+func registerStructSecrets(v any, prefix string) {
+	walkStructFields(v, prefix, func(key string, field reflect.StructField) {
+		t := field.Type
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if field.Tag.Get("secret") == "true" || t == reflect.TypeFor[Secret]() {
+			RedactKeys(key)
+		}
+	})
+}
+
+// resetSecrets restores the default redaction patterns.
+func resetSecrets() {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	secretPatterns = slices.Clone(defaultSecretPatterns)
+}
+
+// redactSecrets wraps the values of secret keys in data, the (sub)tree rooted
+// at prefix, in [Secret].
+func redactSecrets(data map[string]any, prefix string) {
+	for k, v := range data {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		data[k] = redactValue(v, key, false)
+	}
+}
+
+// redactValue redacts v if key is a secret key or force is set. Nested maps
+// and slices under a secret key are redacted element by element.
+func redactValue(v any, key string, force bool) any {
+	force = force || isSecretKey(key)
+
+	switch val := v.(type) {
+	case Secret:
+		return val
+	case map[string]any:
+		for k, item := range val {
+			val[k] = redactValue(item, key+"."+k, force)
+		}
+		return val
+	case []any:
+		for i, item := range val {
+			val[i] = redactValue(item, key, force)
+		}
+		return val
+	case []string:
+		if !force {
+			return val
+		}
+		result := make([]any, len(val))
+		for i, item := range val {
+			result[i] = Secret(item)
+		}
+		return result
+	default:
+		if force {
+			return Secret(toString(val))
+		}
+		return v
+	}
+}
+
+// revealValue returns the actual value of v if it is a [Secret], and v
+// otherwise. Unlike revealSecrets, it does not modify maps and lists.
+func revealValue(v any) any {
+	if secret, ok := v.(Secret); ok {
+		return secret.Reveal()
+	}
+	return v
+}
+
+// revealSecrets replaces [Secret] values in v with their actual value, so
+// that they survive a YAML round trip.
+func revealSecrets(v any) any {
+	switch val := v.(type) {
+	case Secret:
+		return val.Reveal()
+	case map[string]any:
+		for k, item := range val {
+			val[k] = revealSecrets(item)
+		}
+		return val
+	case []any:
+		for i, item := range val {
+			val[i] = revealSecrets(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
@@ -25,13 +25,50 @@ package config
 //
 //	name := config.GetString("app.name")  // returns "myapp"
 func Set(key string, value any) {
-	setInMap(key, value)
+	std.Set(key, value)
+}
+
+// Set is like [Set] but stores the value in c, in the highest layer ranked
+// below the environment.
+func (c *Config) Set(key string, value any) {
+	c.setInMap(key, value)
+}
+
+// SetDefault stores a default value for the given key using dot notation.
+//
+// Defaults form the lowest-priority layer: they are used only when the key is
+// set neither in environment variables nor in the config file. [IsSet] reports
+// true for keys that have a default, and [Origin] reports them as "default".
+//
+// Defaults are also used by [Unmarshal] and [UnmarshalKey]. Struct fields can
+// declare their defaults with the `default` tag instead.
+//
+// Usage:
+//
+//	config.SetDefault("http.port", 8080)
+//	config.SetDefault("http.read_timeout", "30s")
+//	config.Init()
+//
+//	port := config.GetInt("http.port")  // returns 8080 unless set in config.yaml or HTTP_PORT
+func SetDefault(key string, value any) {
+	std.SetDefault(key, value)
+}
+
+// SetDefault is like [SetDefault] but stores the default in c.
+func (c *Config) SetDefault(key string, value any) {
+	c.setDefault(key, value)
 }
 
 // Reset clears all configuration data from memory.
 //
 // This function removes all key-value pairs that were loaded from config.yaml
-// or set programmatically via [Set]. It does not affect environment variables.
+// or set programmatically via [Set], as well as defaults (see [SetDefault]),
+// registered aliases (see [Alias]), recorded deprecations, redacted keys
+// added via [RedactKeys], cached secrets (see [RegisterResolver]), include
+// directories allowed via [AllowIncludes], validators (see [AddValidator]),
+// the outcome of the last reload (see [LastReload]) and watchers (see
+// [Watch]), which stop receiving updates.
+// It does not affect environment variables.
 //
 // This is primarily intended for testing purposes to ensure a clean state
 // between test cases.
@@ -44,7 +81,18 @@ func Set(key string, value any) {
 //	    // ... run test assertions
 //	}
 func Reset() {
-	mu.Lock()
-	defer mu.Unlock()
-	configData = make(map[string]any)
+	std.mu.Lock()
+	std.snap.Store(newDefault().Snapshot())
+	std.validators = nil
+	std.secrets = nil
+	std.mu.Unlock()
+
+	std.watchMu.Lock()
+	std.watchers = nil
+	std.watchMu.Unlock()
+
+	resetAliases()
+	resetSecrets()
+	resetIncludes()
+	resetReloads()
 }
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"slices"
+)
+
+// Snapshot is an immutable, consistent view of the configuration of a
+// [Config] at a point in time.
+//
+// A Config publishes a new Snapshot every time it changes (e.g., on [Set] or
+// [Config.Load]), so reads from a Snapshot are lock-free and never observe a
+// partial update. A Snapshot has the same getters as the package, and every
+// map or slice they return is a copy that callers may modify freely.
+//
+// Environment variables are captured when the environment source is loaded
+// (see [Env]), e.g. by [Init].
+type Snapshot struct {
+	layers    []*layer   // lowest priority first
+	conflicts []Conflict // keys overwritten by config fragments (see [SetConfDir])
+}
+
+// GetSnapshot returns the current [Snapshot] of the configuration.
+//
+// Use it to read several values that must be consistent with each other, e.g.
+// while the configuration may be reloaded concurrently.
+//
+// Usage:
+//
+//	snap := config.GetSnapshot()
+//	host := snap.GetString("database.host")
+//	port := snap.GetInt("database.port")  // from the same version as host
+func GetSnapshot() *Snapshot {
+	return std.Snapshot()
+}
+
+// Snapshot returns the current [Snapshot] of c.
+func (c *Config) Snapshot() *Snapshot {
+	return c.snap.Load()
+}
+
+// Restore makes s the current snapshot of c, e.g. to roll back changes made
+// since s was taken. Watchers are notified (see [Watch]).
+//
+// s may come from another Config: since snapshots are immutable, both
+// Configs can share it safely, but c then loads the sources of s on
+// [Config.Load].
+//
+// Usage:
+//
+//	before := config.GetSnapshot()
+//	config.Set("feature.enabled", true)
+//	// ...
+//	config.Default().Restore(before)
+func (c *Config) Restore(s *Snapshot) {
+	c.mu.Lock()
+	defer c.notify() // after c.mu is released
+	defer c.mu.Unlock()
+
+	c.snap.Store(s)
+}
+
+// update publishes a new snapshot built by fn from a copy of the current one
+// (see [Snapshot.clone]). Updates are serialized by c.mu.
+func (c *Config) update(fn func(next *Snapshot)) {
+	c.mu.Lock()
+	defer c.notify() // after c.mu is released
+	defer c.mu.Unlock()
+
+	next := c.snap.Load().clone()
+	fn(next)
+	c.snap.Store(next)
+	next.reportDeprecations()
+}
+
+// notify calls the watchers of c (see [Watch]). It must be called without
+// holding c.mu, since watchers may read or update c.
+func (c *Config) notify() {
+	c.watchMu.Lock()
+	watchers := slices.Clone(c.watchers)
+	c.watchMu.Unlock()
+
+	for _, w := range watchers {
+		w.fn()
+	}
+}
+
+// watcher is a function called by [Config.notify], registered by pointer so
+// that it can be removed (see [Live.Close])
+type watcher struct {
+	fn func()
+}
+
+// clone returns a copy of s whose layers may be modified without affecting s.
+// The data of the layers is still shared: use [layer.set] to modify it.
+func (s *Snapshot) clone() *Snapshot {
+	next := &Snapshot{layers: make([]*layer, len(s.layers)), conflicts: s.conflicts}
+	for i, l := range s.layers {
+		cp := *l
+		next.layers[i] = &cp
+	}
+	return next
+}
+
+// lookupEnv looks up an environment variable in the environment layers of s,
+// the highest first, or in the process environment if s has none
+func (s *Snapshot) lookupEnv(name string) (string, bool) {
+	hasEnv := false
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		if l := s.layers[i]; l.kind == envLayer && l.lookup != nil {
+			if value, ok := l.lookup(name); ok {
+				return value, true
+			}
+			hasEnv = true
+		}
+	}
+	if !hasEnv {
+		return os.LookupEnv(name)
+	}
+	return "", false
+}
+
+// set stores a copy of value at key, copying the data of the layer first since
+// it may be shared with published snapshots
+func (l *layer) set(key string, value any) {
+	data := map[string]any{}
+	if l.data != nil {
+		data = deepCopy(l.data).(map[string]any)
+	}
+	setPath(data, key, deepCopy(value))
+	l.data = data
+
+	if l.origins != nil {
+		l.origins = maps.Clone(l.origins)
+		forgetOrigins(l.origins, key)
+	}
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Run("is not affected by later changes", func(t *testing.T) {
+		Reset()
+		Set("database.host", "localhost")
+
+		snap := GetSnapshot()
+		Set("database.host", "db.internal")
+		SetDefault("database.port", 5432)
+
+		if got := snap.GetString("database.host"); got != "localhost" {
+			t.Errorf("snap.GetString(database.host) = %q, want %q", got, "localhost")
+		}
+		if snap.IsSet("database.port") {
+			t.Error("snap.IsSet(database.port) = true, want false")
+		}
+		if got := GetString("database.host"); got != "db.internal" {
+			t.Errorf("GetString(database.host) = %q, want %q", got, "db.internal")
+		}
+	})
+
+	t.Run("returned maps and slices are copies", func(t *testing.T) {
+		Reset()
+		Set("database", map[string]any{"host": "localhost"})
+		Set("tags", []string{"a", "b"})
+
+		m := GetStringMap("database")
+		m["host"] = "changed"
+		tags := GetStringSlice("tags")
+		tags[0] = "changed"
+
+		if got := GetString("database.host"); got != "localhost" {
+			t.Errorf("GetString(database.host) = %q, want %q", got, "localhost")
+		}
+		if got := GetStringSlice("tags"); got[0] != "a" {
+			t.Errorf("GetStringSlice(tags) = %v, want [a b]", got)
+		}
+	})
+
+	t.Run("Restore rolls back changes", func(t *testing.T) {
+		Reset()
+		Set("database.host", "localhost")
+		snap := GetSnapshot()
+		Set("database.host", "db.internal")
+
+		Default().Restore(snap)
+		if got := GetString("database.host"); got != "localhost" {
+			t.Errorf("GetString(database.host) = %q, want %q", got, "localhost")
+		}
+	})
+
+	t.Run("values passed to Set are copied", func(t *testing.T) {
+		Reset()
+		db := map[string]any{"host": "localhost"}
+		Set("database", db)
+		db["host"] = "changed"
+
+		if got := GetString("database.host"); got != "localhost" {
+			t.Errorf("GetString(database.host) = %q, want %q", got, "localhost")
+		}
+	})
+}
+
+func TestSnapshotConcurrency(t *testing.T) {
+	values := map[string]any{"version": 0}
+	version := 0
+	var mu sync.Mutex
+	src := sourceFunc(func(ctx context.Context) (map[string]any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		version++
+		return map[string]any{"a": version, "b": version, "list": []any{version}}, nil
+	})
+
+	cfg, err := New(WithSources(Map("base", values), src))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				snap := cfg.Snapshot()
+				if a, b := snap.GetInt("a"), snap.GetInt("b"); a != b {
+					t.Errorf("inconsistent snapshot: a = %d, b = %d", a, b)
+					return
+				}
+				_ = cfg.AllSettings()
+				_ = cfg.GetIntSlice("list")
+			}
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if err := cfg.Load(context.Background()); err != nil {
+					t.Errorf("Load() error = %v", err)
+					return
+				}
+				cfg.Set(fmt.Sprintf("writer%d", i), j)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if a := cfg.GetInt("a"); a != 101 {
+		t.Errorf("GetInt(a) = %d, want %d", a, 101)
+	}
+}
+
+// sourceFunc is a Source loading values from a function
+type sourceFunc func(ctx context.Context) (map[string]any, error)
+
+func (f sourceFunc) Name() string { return "func" }
+
+func (f sourceFunc) Load(ctx context.Context) (map[string]any, error) { return f(ctx) }
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// Source is a source of configuration values, loaded into one layer of a
+// [Config] (see [WithSources]).
+//
+// Load returns the values as a nested map, like a parsed YAML document. Keys
+// are taken as is: a key containing dots (e.g., a host name) is one key.
+// Name identifies the source in errors and in [Config.Origin].
+type Source interface {
+	Load(ctx context.Context) (map[string]any, error)
+	Name() string
+}
+
+// envSource is implemented by sources whose values are environment variables,
+// addressed by UPPER_SNAKE_CASE name (e.g., DB_HOST) rather than by key.
+// Such sources are looked up with [envName] and converted like environment
+// variables by the getters.
+type envSource interface {
+	Source
+	// lookupFunc returns the lookup function of the layer, given the values
+	// returned by Load (nil before the first load).
+	lookupFunc(vars map[string]any) func(string) (string, bool)
+}
+
+type envLookupKey struct{}
+
+// withEnvLookup returns a copy of ctx in which sources read environment
+// variables with lookup
+func withEnvLookup(ctx context.Context, lookup func(string) (string, bool)) context.Context {
+	return context.WithValue(ctx, envLookupKey{}, lookup)
+}
+
+// envLookup returns the lookup function set with withEnvLookup, or
+// os.LookupEnv
+func envLookup(ctx context.Context) func(string) (string, bool) {
+	if lookup, ok := ctx.Value(envLookupKey{}).(func(string) (string, bool)); ok {
+		return lookup
+	}
+	return os.LookupEnv
+}
+
+// File returns a [Source] that reads a YAML file.
+//
+// Encrypted values (ENC[...], see [Keyring.Encrypt]) are decrypted when the
+// file is loaded.
+func File(path string) Source {
+	return &fileSource{name: path, path: path}
+}
+
+type fileSource struct {
+	name string
+	path string
+	dir  string // directory of config fragments, if any (see [ConfDir])
+}
+
+func (s *fileSource) Name() string {
+	return s.name
+}
+
+func (s *fileSource) Load(ctx context.Context) (map[string]any, error) {
+	data, _, _, err := s.loadOrigins(ctx)
+	return data, err
+}
+
+// Map returns a [Source] that holds the given values, e.g. for test overrides.
+// Keys may use dot notation:
+//
+//	config.Map("overrides", map[string]any{"http.port": 9000})
+func Map(name string, values map[string]any) Source {
+	return &mapSource{name: name, values: values}
+}
+
+type mapSource struct {
+	name   string
+	values map[string]any
+}
+
+func (s *mapSource) Name() string {
+	return s.name
+}
+
+func (s *mapSource) Load(ctx context.Context) (map[string]any, error) {
+	return expandKeys(deepCopy(s.values).(map[string]any)), nil
+}
+
+// Defaults returns a [Source] holding default values, which form the default
+// layer (see [SetDefault]). Keys may use dot notation.
+func Defaults(values map[string]any) Source {
+	return &defaultsSource{mapSource{name: "default", values: values}}
+}
+
+type defaultsSource struct {
+	mapSource
+}
+
+// Env returns a [Source] for the process environment variables.
+//
+// The variables are read when the source is loaded, so looking them up is
+// cheap; call [Config.Load] to pick up variables set afterwards. Until the
+// source is loaded, variables are looked up in the process environment.
+func Env() Source {
+	return envVars{}
+}
+
+type envVars struct{}
+
+func (envVars) Name() string {
+	return "env"
+}
+
+func (envVars) Load(ctx context.Context) (map[string]any, error) {
+	vars := make(map[string]any)
+	for _, kv := range os.Environ() {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			vars[name] = value
+		}
+	}
+	return vars, nil
+}
+
+func (envVars) lookupFunc(vars map[string]any) func(string) (string, bool) {
+	if vars == nil {
+		return os.LookupEnv
+	}
+	return mapLookup(vars)
+}
+
+// Dotenv returns a [Source] that reads environment variables from a .env
+// file without modifying the process environment. Like [Env], variables are
+// addressed by UPPER_SNAKE_CASE name. A missing file is not an error.
+func Dotenv(path string) Source {
+	return &dotenvSource{path: path}
+}
+
+type dotenvSource struct {
+	path string
+}
+
+func (s *dotenvSource) Name() string {
+	return "dotenv"
+}
+
+func (s *dotenvSource) Load(ctx context.Context) (map[string]any, error) {
+	vars := make(map[string]any)
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return vars, nil
+	}
+	err := parseDotenv(s.path, func(key, value string) {
+		vars[key] = value
+	})
+	return vars, err
+}
+
+func (s *dotenvSource) lookupFunc(vars map[string]any) func(string) (string, bool) {
+	return mapLookup(vars)
+}
+
+// EnvMap returns a [Source] of environment variables held in vars instead of
+// the process environment, e.g. to test environment overrides in parallel.
+// Like [Env], variables are addressed by UPPER_SNAKE_CASE name:
+//
+//	config.EnvMap(map[string]string{"HTTP_PORT": "9000"})
+func EnvMap(vars map[string]string) Source {
+	return &envMapSource{vars: vars}
+}
+
+type envMapSource struct {
+	vars map[string]string
+}
+
+func (s *envMapSource) Name() string {
+	return "env"
+}
+
+func (s *envMapSource) Load(ctx context.Context) (map[string]any, error) {
+	vars := make(map[string]any, len(s.vars))
+	for name, value := range s.vars {
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+func (s *envMapSource) lookupFunc(vars map[string]any) func(string) (string, bool) {
+	return mapLookup(vars)
+}
+
+// mapLookup returns a lookup function for environment variables loaded in vars
+func mapLookup(vars map[string]any) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		val, ok := vars[name]
+		if !ok {
+			return "", false
+		}
+		return toString(val), true
+	}
+}
@@ -0,0 +1,224 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type failingSource struct{}
+
+func (failingSource) Name() string { return "failing" }
+
+func (failingSource) Load(ctx context.Context) (map[string]any, error) {
+	return nil, errors.New("unavailable")
+}
+
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return path
+}
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	configPath := writeTestFile(t, dir, "config.yaml", "app:\n  env: local\n  name: from_file\nhttp:\n  port: 8080\n")
+	dotenvPath := writeTestFile(t, dir, ".env", "APP_NAME=from_dotenv\nTEST_SOURCE_ONLY=1\n")
+
+	t.Run("sources are merged by priority", func(t *testing.T) {
+		os.Setenv("HTTP_PORT", "3000")
+		defer os.Unsetenv("HTTP_PORT")
+
+		cfg, err := New(WithSources(
+			Defaults(map[string]any{"http.port": 80, "http.host": "0.0.0.0"}),
+			File(configPath),
+			Dotenv(dotenvPath),
+			Env(),
+			Map("flags", map[string]any{"app.env": "flag"}),
+		))
+		if err != nil {
+			t.Fatalf("New returned error: %v", err)
+		}
+
+		tests := []struct {
+			key, want, origin string
+		}{
+			{"http.host", "0.0.0.0", "default"},
+			{"app.name", "from_dotenv", "dotenv"},
+			{"http.port", "3000", "env"},
+			{"app.env", "flag", "flags"},
+		}
+		for _, tt := range tests {
+			if got := cfg.GetString(tt.key); got != tt.want {
+				t.Errorf("GetString(%s) = %q, want %q", tt.key, got, tt.want)
+			}
+			if got := cfg.Origin(tt.key); got != tt.origin {
+				t.Errorf("Origin(%s) = %q, want %q", tt.key, got, tt.origin)
+			}
+		}
+
+		want := map[string]any{
+			"app":  map[string]any{"env": "flag", "name": "from_dotenv"},
+			"http": map[string]any{"port": 3000, "host": "0.0.0.0"},
+		}
+		if got := cfg.AllSettings(); !reflect.DeepEqual(got, want) {
+			t.Errorf("AllSettings() = %v, want %v", got, want)
+		}
+
+		if _, ok := os.LookupEnv("TEST_SOURCE_ONLY"); ok {
+			t.Error("Dotenv source modified the process environment")
+		}
+	})
+
+	t.Run("Set stores values below the environment", func(t *testing.T) {
+		os.Setenv("APP_NAME", "from_env")
+		defer os.Unsetenv("APP_NAME")
+
+		cfg, err := New(WithSources(File(configPath), Env(), Map("flags", map[string]any{"app.env": "flag"})))
+		if err != nil {
+			t.Fatalf("New returned error: %v", err)
+		}
+
+		cfg.Set("app.name", "from_set")
+		cfg.Set("app.env", "from_set")
+		cfg.Set("app.debug", true)
+		if got := cfg.GetString("app.name"); got != "from_env" {
+			t.Errorf("GetString(app.name) = %q, want %q", got, "from_env")
+		}
+		if got := cfg.GetString("app.env"); got != "flag" {
+			t.Errorf("GetString(app.env) = %q, want %q", got, "flag")
+		}
+		if !cfg.GetBool("app.debug") {
+			t.Error("GetBool(app.debug) = false, want true")
+		}
+	})
+
+	t.Run("keys of YAML files are not expanded", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("hosts:\n  api.example.com: 10.0.0.1\n"), 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cfg, err := New(WithSources(File(path)))
+		if err != nil {
+			t.Fatalf("New returned error: %v", err)
+		}
+
+		want := map[string]string{"api.example.com": "10.0.0.1"}
+		if got := cfg.GetStringMapString("hosts"); !maps.Equal(got, want) {
+			t.Errorf("GetStringMapString(hosts) = %v, want %v", got, want)
+		}
+		if cfg.IsSet("hosts.api") {
+			t.Error("IsSet(hosts.api) = true, want false")
+		}
+	})
+
+	t.Run("environment is read at load", func(t *testing.T) {
+		cfg, err := New(WithSources(File(configPath), Env()))
+		if err != nil {
+			t.Fatalf("New returned error: %v", err)
+		}
+
+		os.Setenv("APP_NAME", "from_env")
+		defer os.Unsetenv("APP_NAME")
+		if got := cfg.GetString("app.name"); got != "from_file" {
+			t.Errorf("GetString(app.name) = %q, want %q", got, "from_file")
+		}
+		if err := cfg.Load(context.Background()); err != nil {
+			t.Fatalf("Load returned error: %v", err)
+		}
+		if got := cfg.GetString("app.name"); got != "from_env" {
+			t.Errorf("GetString(app.name) after Load = %q, want %q", got, "from_env")
+		}
+	})
+
+	t.Run("environment from a map", func(t *testing.T) {
+		os.Setenv("APP_NAME", "from_process")
+		defer os.Unsetenv("APP_NAME")
+
+		cfg, err := New(WithSources(File(configPath), EnvMap(map[string]string{"APP_DEBUG": "true"})))
+		if err != nil {
+			t.Fatalf("New returned error: %v", err)
+		}
+		if got := cfg.GetString("app.name"); got != "from_file" {
+			t.Errorf("GetString(app.name) = %q, want %q", got, "from_file")
+		}
+		if !cfg.GetBool("app.debug") || cfg.Origin("app.debug") != "env" {
+			t.Errorf("GetBool(app.debug) = %v from %q, want true from env", cfg.GetBool("app.debug"), cfg.Origin("app.debug"))
+		}
+	})
+
+	t.Run("empty Config", func(t *testing.T) {
+		cfg, err := New()
+		if err != nil {
+			t.Fatalf("New returned error: %v", err)
+		}
+		cfg.SetDefault("http.port", 8080)
+		cfg.Set("http.host", "localhost")
+
+		if got := cfg.GetInt("http.port"); got != 8080 {
+			t.Errorf("GetInt(http.port) = %v, want %v", got, 8080)
+		}
+		if got := cfg.GetString("http.host"); got != "localhost" {
+			t.Errorf("GetString(http.host) = %q, want %q", got, "localhost")
+		}
+		if IsSet("http.host") {
+			t.Error("Config created by New modified the default Config")
+		}
+	})
+
+	t.Run("Load is all or nothing", func(t *testing.T) {
+		if _, err := New(WithSources(File(configPath), failingSource{})); err == nil {
+			t.Fatal("New with failing source returned nil error")
+		}
+		missing := File(filepath.Join(t.TempDir(), "missing.yaml"))
+		_, err := New(WithSources(missing, failingSource{}))
+		if err == nil || !strings.Contains(err.Error(), "missing.yaml") || !strings.Contains(err.Error(), "load failing") {
+			t.Errorf("New() error = %v, want the errors of both sources", err)
+		}
+
+		path := writeTestFile(t, t.TempDir(), "config.yaml", "app:\n  env: v1\n")
+		cfg, err := New(WithSources(File(path)))
+		if err != nil {
+			t.Fatalf("New returned error: %v", err)
+		}
+
+		writeTestFile(t, filepath.Dir(path), "config.yaml", "app: [invalid\n")
+		if err := cfg.Load(context.Background()); err == nil {
+			t.Fatal("Load of invalid file returned nil error")
+		}
+		if got := cfg.GetString("app.env"); got != "v1" {
+			t.Errorf("GetString(app.env) = %q after failed Load, want %q", got, "v1")
+		}
+
+		writeTestFile(t, filepath.Dir(path), "config.yaml", "app:\n  env: v2\n")
+		if err := cfg.Load(context.Background()); err != nil {
+			t.Fatalf("Load returned error: %v", err)
+		}
+		if got := cfg.GetString("app.env"); got != "v2" {
+			t.Errorf("GetString(app.env) = %q, want %q", got, "v2")
+		}
+	})
+}
+
+func TestExpandKeys(t *testing.T) {
+	got := expandKeys(map[string]any{
+		"http.port": 9000,
+		"http":      map[string]any{"host": "localhost"},
+		"app":       map[string]any{"tls.enabled": true},
+	})
+	want := map[string]any{
+		"http": map[string]any{"port": 9000, "host": "localhost"},
+		"app":  map[string]any{"tls": map[string]any{"enabled": true}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expandKeys() = %v, want %v", got, want)
+	}
+}
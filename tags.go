@@ -0,0 +1,74 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// walkStructFields calls fn for every field of the struct pointed to by v,
+// recursing into nested structs. The key passed to fn is the field's dot
+// notation key, derived from its `yaml` tag the same way yaml.v3 does and
+// prefixed with prefix (if not empty).
+func walkStructFields(v any, prefix string, fn func(key string, field reflect.StructField)) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return
+	}
+	walkStructType(t, prefix, fn, map[reflect.Type]bool{})
+}
+
+// walkStructType implements walkStructFields. visiting holds the struct types
+// on the current path and stops recursive types from looping forever.
+func walkStructType(t reflect.Type, prefix string, fn func(key string, field reflect.StructField), visiting map[reflect.Type]bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || visiting[t] {
+		return
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, inline, ok := yamlFieldName(field)
+		if !ok {
+			continue
+		}
+		if inline {
+			walkStructType(field.Type, prefix, fn, visiting)
+			continue
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		fn(key, field)
+		walkStructType(field.Type, key, fn, visiting)
+	}
+}
+
+// yamlFieldName returns the YAML key of a struct field, whether the field is
+// inlined, and false if the field is skipped by yaml.v3 (`yaml:"-"`).
+func yamlFieldName(field reflect.StructField) (name string, inline bool, ok bool) {
+	tag := field.Tag.Get("yaml")
+	if tag == "-" {
+		return "", false, false
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "inline" {
+			inline = true
+		}
+	}
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, inline, true
+}
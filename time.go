@@ -0,0 +1,339 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+// ParseDuration parses a duration such as 300ms, 1h30m, 7d, 2w or 1d12h, or
+// an ISO 8601 duration such as PT30S or P1DT12H. Days are 24 hours and weeks
+// 7 days; ISO 8601 years and months are not supported since their length
+// varies.
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+
+	rest, neg := strings.CutPrefix(s, "-")
+	if !neg {
+		rest = strings.TrimPrefix(rest, "+")
+	}
+	var d time.Duration
+	var err error
+	if strings.HasPrefix(rest, "P") || strings.HasPrefix(rest, "p") {
+		d, err = parseISODuration(rest)
+	} else {
+		d, err = parseDays(rest)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+// parseDays parses weeks and days followed by an optional Go duration, e.g.
+// 2w, 7d or 1d12h30m
+func parseDays(s string) (time.Duration, error) {
+	var total time.Duration
+	rest := s
+	for rest != "" {
+		i := strings.IndexFunc(rest, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.'
+		})
+		if i <= 0 || (rest[i] != 'd' && rest[i] != 'w') {
+			break
+		}
+		n, err := strconv.ParseFloat(rest[:i], 64)
+		if err != nil {
+			return 0, err
+		}
+		unit := day
+		if rest[i] == 'w' {
+			unit = week
+		}
+		if total, err = addDuration(total, n, unit); err != nil {
+			return 0, err
+		}
+		rest = rest[i+1:]
+	}
+	if rest == s {
+		return 0, fmt.Errorf("missing days or weeks")
+	}
+	if rest != "" {
+		d, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, err
+		}
+		if total, err = addDuration(total, 1, d); err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// parseISODuration parses an ISO 8601 duration without years and months,
+// e.g. P1W, P2DT3H or PT0.5S
+func parseISODuration(s string) (time.Duration, error) {
+	s = strings.ToUpper(s[1:])
+	datePart, timePart, hasTime := strings.Cut(s, "T")
+	if s == "" || (hasTime && timePart == "") {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	var total time.Duration
+	parts := []struct {
+		value string
+		units map[byte]time.Duration
+	}{
+		{datePart, map[byte]time.Duration{'W': week, 'D': day}},
+		{timePart, map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}},
+	}
+	for _, part := range parts {
+		rest := part.value
+		for rest != "" {
+			i := strings.IndexFunc(rest, func(r rune) bool {
+				return (r < '0' || r > '9') && r != '.' && r != ','
+			})
+			if i <= 0 {
+				return 0, fmt.Errorf("missing unit")
+			}
+			unit, ok := part.units[rest[i]]
+			if !ok {
+				return 0, fmt.Errorf("unsupported unit %q", rest[i])
+			}
+			n, err := strconv.ParseFloat(strings.ReplaceAll(rest[:i], ",", "."), 64)
+			if err != nil {
+				return 0, err
+			}
+			if total, err = addDuration(total, n, unit); err != nil {
+				return 0, err
+			}
+			rest = rest[i+1:]
+		}
+	}
+	return total, nil
+}
+
+// addDuration returns total + n*unit, or an error if it overflows a
+// time.Duration. total and n must not be negative.
+func addDuration(total time.Duration, n float64, unit time.Duration) (time.Duration, error) {
+	v := n * float64(unit)
+	if v >= math.MaxInt64 || time.Duration(v) > math.MaxInt64-total {
+		return 0, fmt.Errorf("duration out of range")
+	}
+	return total + time.Duration(v), nil
+}
+
+// GetTime returns the [time.Time] value associated with the given key,
+// parsed with layout (see [time.Parse]).
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE)
+//  2. Config file value
+//
+// Returns the zero Time if the key is not found or cannot be parsed.
+//
+// An empty layout accepts RFC 3339 timestamps ("2025-03-01T09:00:00Z"),
+// "2025-03-01 09:00:00" (UTC) and dates ("2025-03-01", midnight UTC).
+// Unquoted timestamps in YAML files are always accepted, whatever the layout.
+//
+// Config file example (config.yaml):
+//
+//	promo:
+//	  starts_at: 2025-03-01T09:00:00+01:00
+//	  ends_at: 2025-03-31
+//
+// Environment variable override:
+//
+//	export PROMO_ENDS_AT=2025-04-15
+//
+// Usage:
+//
+//	now := time.Now()
+//	active := now.After(config.GetTime("promo.starts_at", "")) &&
+//	    now.Before(config.GetTime("promo.ends_at", time.DateOnly))
+func GetTime(key, layout string) time.Time {
+	return std.GetTime(key, layout)
+}
+
+// GetTime is like [GetTime] but reads from c.
+func (c *Config) GetTime(key, layout string) time.Time {
+	return c.Snapshot().GetTime(key, layout)
+}
+
+// GetTime is like [GetTime] but reads from s.
+func (s *Snapshot) GetTime(key, layout string) time.Time {
+	if val, ok := s.getEnvValue(key); ok {
+		return toTime(val, layout)
+	}
+	if val, ok := s.getFromMap(key); ok {
+		return toTime(val, layout)
+	}
+	return time.Time{}
+}
+
+// GetLocation returns the time zone named by the value of the given key, such
+// as "Europe/Paris", "UTC" or "Local" (see [time.LoadLocation]).
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE)
+//  2. Config file value
+//
+// Returns UTC if the key is not found, and an error if the time zone is
+// unknown. Time zones are read from the system's database; containers
+// without one (e.g. built FROM scratch) should import the
+// [github.com/stanza-go/config/tzdata] package to embed it.
+//
+// Config file example (config.yaml):
+//
+//	reports:
+//	  timezone: Europe/Paris
+//
+// Environment variable override:
+//
+//	export REPORTS_TIMEZONE=America/New_York
+//
+// Usage:
+//
+//	loc, err := config.GetLocation("reports.timezone")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	midnight := time.Date(y, m, d, 0, 0, 0, 0, loc)
+func GetLocation(key string) (*time.Location, error) {
+	return std.GetLocation(key)
+}
+
+// GetLocation is like [GetLocation] but reads from c.
+func (c *Config) GetLocation(key string) (*time.Location, error) {
+	return c.Snapshot().GetLocation(key)
+}
+
+// GetLocation is like [GetLocation] but reads from s.
+func (s *Snapshot) GetLocation(key string) (*time.Location, error) {
+	val, ok := s.getRaw(key)
+	if !ok {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(strings.TrimSpace(val))
+	if err != nil {
+		return nil, fmt.Errorf("config: %s: %w", key, err)
+	}
+	return loc, nil
+}
+
+// GetDurationSlice returns a [time.Duration] slice value associated with the
+// given key.
+//
+// Returns nil if the key is not found.
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE, comma-separated)
+//  2. Config file value
+//
+// Each element accepts the formats of [GetDuration].
+//
+// Config file example (config.yaml):
+//
+//	retry:
+//	  backoff:
+//	    - 100ms
+//	    - 500ms
+//	    - 2s
+//
+// Environment variable override (comma-separated):
+//
+//	export RETRY_BACKOFF=100ms,1s,5s
+//
+// Empty values and invalid durations are filtered out:
+//
+//	export RETRY_BACKOFF=1s,,soon,5s  # returns [1s, 5s]
+//
+// Usage:
+//
+//	for _, backoff := range config.GetDurationSlice("retry.backoff") {
+//	    if err := call(); err == nil {
+//	        break
+//	    }
+//	    time.Sleep(backoff)
+//	}
+func GetDurationSlice(key string) []time.Duration {
+	return std.GetDurationSlice(key)
+}
+
+// GetDurationSlice is like [GetDurationSlice] but reads from c.
+func (c *Config) GetDurationSlice(key string) []time.Duration {
+	return c.Snapshot().GetDurationSlice(key)
+}
+
+// GetDurationSlice is like [GetDurationSlice] but reads from s.
+func (s *Snapshot) GetDurationSlice(key string) []time.Duration {
+	if val, ok := s.getEnvValue(key); ok {
+		return splitAndTrimDurationSlice(val)
+	}
+	if val, ok := s.getFromMap(key); ok {
+		return toDurationSlice(val)
+	}
+	return nil
+}
+
+func toTime(v any, layout string) time.Time {
+	switch val := v.(type) {
+	case time.Time:
+		return val
+	case string:
+		val = strings.TrimSpace(val)
+		if layout != "" {
+			t, _ := time.Parse(layout, val)
+			return t
+		}
+		for _, layout := range []string{time.RFC3339Nano, time.DateTime, time.DateOnly} {
+			if t, err := time.Parse(layout, val); err == nil {
+				return t
+			}
+		}
+		return time.Time{}
+	default:
+		return time.Time{}
+	}
+}
+
+func toDurationSlice(v any) []time.Duration {
+	switch val := v.(type) {
+	case []time.Duration:
+		return slices.Clone(val)
+	case []any:
+		result := make([]time.Duration, len(val))
+		for i, item := range val {
+			result[i] = toDuration(item)
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// splitAndTrimDurationSlice splits a comma-separated string into a slice of
+// durations, skipping empty values and values that cannot be parsed
+func splitAndTrimDurationSlice(s string) []time.Duration {
+	result := []time.Duration{}
+	for _, part := range splitAndTrimStringSlice(s) {
+		if d, err := ParseDuration(part); err == nil {
+			result = append(result, d)
+		}
+	}
+	return result
+}
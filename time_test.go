@@ -0,0 +1,216 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"300ms":     300 * time.Millisecond,
+		"1h30m":     90 * time.Minute,
+		"7d":        7 * 24 * time.Hour,
+		"2w":        14 * 24 * time.Hour,
+		"1.5d":      36 * time.Hour,
+		"1d12h":     36 * time.Hour,
+		"1w2d3h4m":  (9*24+3)*time.Hour + 4*time.Minute,
+		"-1d":       -24 * time.Hour,
+		" 3d ":      72 * time.Hour,
+		"PT30S":     30 * time.Second,
+		"PT1M":      time.Minute,
+		"P1DT12H":   36 * time.Hour,
+		"P2W":       14 * 24 * time.Hour,
+		"PT0.5S":    500 * time.Millisecond,
+		"PT1H30M5S": time.Hour + 30*time.Minute + 5*time.Second,
+		"pt15m":     15 * time.Minute,
+	}
+	for s, want := range tests {
+		got, err := ParseDuration(s)
+		if err != nil || got != want {
+			t.Errorf("ParseDuration(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+
+	invalid := []string{"", "d", "10", "7days", "1d12", "P", "PT", "P1M", "P1Y", "PT1D", "P1H", "PT5"}
+	// Out of range
+	invalid = append(invalid, "300000w", "P300000W", "106751d23h47m16.854775808s", "PT2562047H47M17S")
+	for _, s := range invalid {
+		if _, err := ParseDuration(s); err == nil {
+			t.Errorf("ParseDuration(%q) error = nil, want error", s)
+		}
+	}
+}
+
+func TestDecodeDurations(t *testing.T) {
+	type Base struct {
+		Timeout time.Duration `yaml:"timeout"`
+	}
+	type Cache struct {
+		Base    `yaml:",inline"`
+		TTL     time.Duration            `yaml:"ttl" default:"7d"`
+		Backoff []time.Duration          `yaml:"backoff"`
+		Expiry  map[string]time.Duration `yaml:"expiry"`
+		Grace   *time.Duration           `yaml:"grace"`
+		Name    string                   `yaml:"name"`
+	}
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		Reset()
+		Set("cache", map[string]any{
+			"timeout": "PT30S",
+			"backoff": []any{"1s", "1d"},
+			"expiry":  map[string]any{"session": "2w"},
+			"grace":   "1d12h",
+			"name":    "1d",
+		})
+
+		var cfg struct {
+			Cache Cache `yaml:"cache"`
+		}
+		if err := Unmarshal(&cfg); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		c := cfg.Cache
+		if c.Timeout != 30*time.Second || c.TTL != 7*24*time.Hour || c.Name != "1d" {
+			t.Errorf("Unmarshal() = %+v", c)
+		}
+		if !slices.Equal(c.Backoff, []time.Duration{time.Second, 24 * time.Hour}) || c.Expiry["session"] != 14*24*time.Hour {
+			t.Errorf("Unmarshal() = %+v", c)
+		}
+		if c.Grace == nil || *c.Grace != 36*time.Hour {
+			t.Errorf("Unmarshal() Grace = %v, want %v", c.Grace, 36*time.Hour)
+		}
+
+		Set("cache.ttl", "soon")
+		if err := Unmarshal(&cfg); err == nil {
+			t.Error("Unmarshal() of an invalid duration error = nil, want error")
+		}
+	})
+
+	t.Run("Watch and GetSlice", func(t *testing.T) {
+		Reset()
+		os.Setenv("SESSION_TTL", "7d")
+		defer os.Unsetenv("SESSION_TTL")
+		Set("caches", []any{map[string]any{"timeout": "1w"}})
+
+		if got := Watch[time.Duration]("session.ttl").Load(); got != 7*24*time.Hour {
+			t.Errorf("Watch(session.ttl).Load() = %v, want %v", got, 7*24*time.Hour)
+		}
+		got, err := GetSlice[Base]("caches")
+		if err != nil || len(got) != 1 || got[0].Timeout != 7*24*time.Hour {
+			t.Errorf("GetSlice(caches) = %+v, %v", got, err)
+		}
+	})
+}
+
+func TestTimeGetters(t *testing.T) {
+	t.Run("GetDuration with days and ISO 8601", func(t *testing.T) {
+		Reset()
+		Set("session.ttl", "7d")
+		Set("cache.ttl", "PT30S")
+		os.Setenv("TOKEN_TTL", "2w")
+		defer os.Unsetenv("TOKEN_TTL")
+
+		if got := GetDuration("session.ttl"); got != 7*24*time.Hour {
+			t.Errorf("GetDuration(session.ttl) = %v, want %v", got, 7*24*time.Hour)
+		}
+		if got := GetDuration("cache.ttl"); got != 30*time.Second {
+			t.Errorf("GetDuration(cache.ttl) = %v, want %v", got, 30*time.Second)
+		}
+		if got := GetDuration("token.ttl"); got != 14*24*time.Hour {
+			t.Errorf("GetDuration(token.ttl) = %v, want %v", got, 14*24*time.Hour)
+		}
+	})
+
+	t.Run("GetTime", func(t *testing.T) {
+		Reset()
+		Set("promo.starts_at", "2025-03-01T09:00:00+01:00")
+		Set("promo.ends_at", "2025-03-31")
+		Set("promo.parsed", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+		Set("promo.custom", "01/04/2025")
+
+		if got, want := GetTime("promo.starts_at", ""), time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Errorf("GetTime(promo.starts_at) = %v, want %v", got, want)
+		}
+		if got, want := GetTime("promo.ends_at", ""), time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Errorf("GetTime(promo.ends_at) = %v, want %v", got, want)
+		}
+		if got, want := GetTime("promo.parsed", time.Kitchen), time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Errorf("GetTime(promo.parsed) = %v, want %v", got, want)
+		}
+		if got, want := GetTime("promo.custom", "02/01/2006"), time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Errorf("GetTime(promo.custom) = %v, want %v", got, want)
+		}
+		if got := GetTime("promo.custom", ""); !got.IsZero() {
+			t.Errorf("GetTime(promo.custom) without layout = %v, want zero", got)
+		}
+		if got := GetTime("promo.missing", ""); !got.IsZero() {
+			t.Errorf("GetTime(promo.missing) = %v, want zero", got)
+		}
+
+		os.Setenv("PROMO_ENDS_AT", "2025-04-15 18:00:00")
+		defer os.Unsetenv("PROMO_ENDS_AT")
+		if got, want := GetTime("promo.ends_at", ""), time.Date(2025, 4, 15, 18, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Errorf("GetTime(promo.ends_at) = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("GetTime from a config file", func(t *testing.T) {
+		Reset()
+		dir := t.TempDir()
+		if err := os.WriteFile(dir+"/config.yaml", []byte("promo:\n  ends_at: 2025-03-31\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		c, err := New(WithSources(File(dir + "/config.yaml")))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if got, want := c.GetTime("promo.ends_at", time.DateOnly), time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Errorf("GetTime(promo.ends_at) = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("GetLocation", func(t *testing.T) {
+		Reset()
+		Set("reports.timezone", "UTC")
+		Set("billing.timezone", "Mars/Olympus_Mons")
+
+		if loc, err := GetLocation("reports.timezone"); err != nil || loc != time.UTC {
+			t.Errorf("GetLocation(reports.timezone) = %v, %v, want UTC", loc, err)
+		}
+		if loc, err := GetLocation("missing.timezone"); err != nil || loc != time.UTC {
+			t.Errorf("GetLocation(missing.timezone) = %v, %v, want UTC", loc, err)
+		}
+		if _, err := GetLocation("billing.timezone"); err == nil {
+			t.Error("GetLocation(billing.timezone) error = nil, want error")
+		}
+
+		os.Setenv("REPORTS_TIMEZONE", "Local")
+		defer os.Unsetenv("REPORTS_TIMEZONE")
+		if loc, err := GetLocation("reports.timezone"); err != nil || loc != time.Local {
+			t.Errorf("GetLocation(reports.timezone) = %v, %v, want Local", loc, err)
+		}
+	})
+
+	t.Run("GetDurationSlice", func(t *testing.T) {
+		Reset()
+		Set("retry.backoff", []any{"100ms", "1s", "1d"})
+
+		want := []time.Duration{100 * time.Millisecond, time.Second, 24 * time.Hour}
+		if got := GetDurationSlice("retry.backoff"); !slices.Equal(got, want) {
+			t.Errorf("GetDurationSlice(retry.backoff) = %v, want %v", got, want)
+		}
+		if got := GetDurationSlice("retry.missing"); got != nil {
+			t.Errorf("GetDurationSlice(retry.missing) = %v, want nil", got)
+		}
+
+		os.Setenv("RETRY_BACKOFF", "1s, ,soon,PT5S")
+		defer os.Unsetenv("RETRY_BACKOFF")
+		want = []time.Duration{time.Second, 5 * time.Second}
+		if got := GetDurationSlice("retry.backoff"); !slices.Equal(got, want) {
+			t.Errorf("GetDurationSlice(retry.backoff) = %v, want %v", got, want)
+		}
+	})
+}
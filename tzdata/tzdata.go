@@ -0,0 +1,13 @@
+// Package tzdata embeds the time zone database in the binary, so that
+// [github.com/stanza-go/config.GetLocation] works in containers without one,
+// such as images built FROM scratch or distroless.
+//
+// Import it for its side effect in the main package:
+//
+//	import _ "github.com/stanza-go/config/tzdata"
+//
+// It adds about 450 KB to the binary. The system database, when present, is
+// still preferred. Building with -tags timetzdata has the same effect.
+package tzdata
+
+import _ "time/tzdata"
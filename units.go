@@ -0,0 +1,607 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ByteSize is a size in bytes, written with SI (kB, MB, GB, ...) or IEC
+// (KiB, MiB, GiB, ...) units in the configuration, e.g. 512MB or 10MiB.
+//
+// Struct fields of type ByteSize are decoded by [Unmarshal]:
+//
+//	type HTTPConfig struct {
+//	    MaxBody config.ByteSize `yaml:"max_body"`  // max_body: 10MiB
+//	}
+type ByteSize int64
+
+// Percent is a fraction written as a percentage in the configuration, e.g.
+// 25% for 0.25. Numbers without a % sign are fractions.
+//
+// Struct fields of type Percent are decoded by [Unmarshal]:
+//
+//	type TracingConfig struct {
+//	    SampleRate config.Percent `yaml:"sample_rate"`  // sample_rate: 25%
+//	}
+type Percent float64
+
+// Rate is a number of events per period, written as count/unit or
+// count/duration in the configuration, e.g. 100/s, 5000/h or 10/500ms.
+//
+// Struct fields of type Rate are decoded by [Unmarshal]:
+//
+//	type LimitConfig struct {
+//	    Requests config.Rate `yaml:"requests"`  // requests: 100/s
+//	}
+type Rate struct {
+	Count float64
+	Per   time.Duration
+}
+
+// byteUnits are the multipliers of the size units, by lower case prefix
+var byteUnits = map[string]float64{
+	"":   1,
+	"k":  1e3,
+	"m":  1e6,
+	"g":  1e9,
+	"t":  1e12,
+	"p":  1e15,
+	"e":  1e18,
+	"ki": 1 << 10,
+	"mi": 1 << 20,
+	"gi": 1 << 30,
+	"ti": 1 << 40,
+	"pi": 1 << 50,
+	"ei": 1 << 60,
+}
+
+// rateUnits are the periods of the rate units
+var rateUnits = map[string]time.Duration{
+	"ms": time.Millisecond, "s": time.Second, "sec": time.Second, "second": time.Second,
+	"m": time.Minute, "min": time.Minute, "minute": time.Minute,
+	"h": time.Hour, "hour": time.Hour, "d": 24 * time.Hour, "day": 24 * time.Hour,
+}
+
+// ParseByteSize parses a size such as 512MB, 10MiB, 1.5G or 1024 (bytes).
+// Units are case-insensitive; k, M, G, ... are powers of 1000 and Ki, Mi,
+// Gi, ... powers of 1024, with or without a trailing B.
+func ParseByteSize(s string) (ByteSize, error) {
+	b, err := parseBytes(s, math.MaxInt64)
+	return ByteSize(b), err
+}
+
+// parseBytes parses a size like ParseByteSize, up to limit bytes. Sizes
+// without a unit are parsed exactly.
+func parseBytes(s string, limit uint64) (uint64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	if i == -1 {
+		i = len(s)
+	}
+	num, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	unit = strings.TrimSuffix(unit, "b")
+	mult, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit", s)
+	}
+	if mult == 1 {
+		if b, err := strconv.ParseUint(num, 10, 64); err == nil && b <= limit {
+			return b, nil
+		}
+	}
+	if n*mult >= float64(limit) {
+		return 0, fmt.Errorf("invalid size %q: out of range", s)
+	}
+	return uint64(n * mult), nil
+}
+
+// String returns the size with the largest IEC unit that represents it
+// exactly, e.g. 10MiB.
+func (b ByteSize) String() string {
+	units := []string{"EiB", "PiB", "TiB", "GiB", "MiB", "KiB"}
+	for i, unit := range units {
+		size := int64(1) << (10 * (len(units) - i))
+		if b != 0 && int64(b)%size == 0 {
+			return strconv.FormatInt(int64(b)/size, 10) + unit
+		}
+	}
+	return strconv.FormatInt(int64(b), 10) + "B"
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	v, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// UnmarshalYAML implements [yaml.Unmarshaler].
+func (b *ByteSize) UnmarshalYAML(node *yaml.Node) error {
+	return unmarshalScalar(node, b)
+}
+
+// ParsePercent parses a percentage such as 25% or 12.5%, or a fraction such
+// as 0.25, and returns the fraction.
+func ParsePercent(s string) (Percent, error) {
+	s = strings.TrimSpace(s)
+	num, pct := strings.CutSuffix(s, "%")
+	f, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percentage %q", s)
+	}
+	if pct {
+		f /= 100
+	}
+	return Percent(f), nil
+}
+
+// String returns the fraction as a percentage, e.g. 25%.
+func (p Percent) String() string {
+	return strconv.FormatFloat(float64(p)*100, 'f', -1, 64) + "%"
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (p Percent) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (p *Percent) UnmarshalText(text []byte) error {
+	v, err := ParsePercent(string(text))
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// UnmarshalYAML implements [yaml.Unmarshaler].
+func (p *Percent) UnmarshalYAML(node *yaml.Node) error {
+	return unmarshalScalar(node, p)
+}
+
+// ParseRate parses a rate such as 100/s, 5000/hour, 1.5/min or 10/500ms.
+// Units are ms, s (sec, second), m (min, minute), h (hour) and d (day); any
+// other period must be a duration (see [time.ParseDuration]).
+func ParseRate(s string) (Rate, error) {
+	s = strings.TrimSpace(s)
+	num, per, ok := strings.Cut(s, "/")
+	if !ok {
+		return Rate{}, fmt.Errorf("invalid rate %q: want count/period", s)
+	}
+	count, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	if err != nil || count < 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q", s)
+	}
+
+	per = strings.ToLower(strings.TrimSpace(per))
+	period, ok := rateUnits[per]
+	if !ok {
+		if period, err = time.ParseDuration(per); err != nil {
+			return Rate{}, fmt.Errorf("invalid rate %q: unknown period", s)
+		}
+	}
+	if period <= 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q: period must be positive", s)
+	}
+	return Rate{Count: count, Per: period}, nil
+}
+
+// PerSecond returns the number of events per second, or 0 for the zero Rate.
+func (r Rate) PerSecond() float64 {
+	if r.Per <= 0 {
+		return 0
+	}
+	return r.Count / r.Per.Seconds()
+}
+
+// Interval returns the time between two events, or 0 if the rate is zero.
+//
+// Usage:
+//
+//	ticker := time.NewTicker(config.GetRate("poll.rate").Interval())
+func (r Rate) Interval() time.Duration {
+	if r.Count <= 0 {
+		return 0
+	}
+	return time.Duration(float64(r.Per) / r.Count)
+}
+
+// String returns the rate as count/period, e.g. 100/s or 10/500ms.
+func (r Rate) String() string {
+	count := strconv.FormatFloat(r.Count, 'f', -1, 64)
+	switch r.Per {
+	case time.Second:
+		return count + "/s"
+	case time.Minute:
+		return count + "/m"
+	case time.Hour:
+		return count + "/h"
+	case 24 * time.Hour:
+		return count + "/d"
+	}
+	return count + "/" + r.Per.String()
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (r Rate) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (r *Rate) UnmarshalText(text []byte) error {
+	v, err := ParseRate(string(text))
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// UnmarshalYAML implements [yaml.Unmarshaler].
+func (r *Rate) UnmarshalYAML(node *yaml.Node) error {
+	return unmarshalScalar(node, r)
+}
+
+// unmarshalScalar decodes a YAML scalar with the UnmarshalText method of v
+func unmarshalScalar(node *yaml.Node, v interface{ UnmarshalText([]byte) error }) error {
+	if node.Kind != yaml.ScalarNode {
+		return errors.New("config: expected a scalar value")
+	}
+	if err := v.UnmarshalText([]byte(node.Value)); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	return nil
+}
+
+// GetBytes returns the size in bytes associated with the given key.
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE)
+//  2. Config file value
+//
+// Returns 0 if the key is not found or cannot be parsed (see [ParseByteSize]).
+//
+// Supported formats:
+//   - SI units: "512MB", "1.5GB", "100k"
+//   - IEC units: "10MiB", "4GiB", "64Ki"
+//   - Integer values: treated as bytes
+//
+// Config file example (config.yaml):
+//
+//	http:
+//	  max_body: 10MiB
+//	cache:
+//	  size: 512MB
+//
+// Environment variable override:
+//
+//	export HTTP_MAX_BODY=32MiB
+//
+// Usage:
+//
+//	r.Body = http.MaxBytesReader(w, r.Body, config.GetBytes("http.max_body"))
+func GetBytes(key string) int64 {
+	return std.GetBytes(key)
+}
+
+// GetBytes is like [GetBytes] but reads from c.
+func (c *Config) GetBytes(key string) int64 {
+	return c.Snapshot().GetBytes(key)
+}
+
+// GetBytes is like [GetBytes] but reads from s.
+func (s *Snapshot) GetBytes(key string) int64 {
+	if val, ok := s.getEnvValue(key); ok {
+		return toBytes(val)
+	}
+	if val, ok := s.getFromMap(key); ok {
+		return toBytes(val)
+	}
+	return 0
+}
+
+// GetBytesOr returns the size in bytes associated with the given key, or
+// defaultValue if the key is not set.
+//
+// Usage:
+//
+//	maxBody := config.GetBytesOr("http.max_body", 1<<20)
+func GetBytesOr(key string, defaultValue int64) int64 {
+	return std.GetBytesOr(key, defaultValue)
+}
+
+// GetBytesOr is like [GetBytesOr] but reads from c.
+func (c *Config) GetBytesOr(key string, defaultValue int64) int64 {
+	return c.Snapshot().GetBytesOr(key, defaultValue)
+}
+
+// GetBytesOr is like [GetBytesOr] but reads from s.
+func (s *Snapshot) GetBytesOr(key string, defaultValue int64) int64 {
+	if s.IsSet(key) {
+		return s.GetBytes(key)
+	}
+	return defaultValue
+}
+
+// GetBytesUint64 is like [GetBytes] but returns a uint64, for sizes of up to
+// 16EiB that do not fit in an int64.
+//
+// Returns 0 if the key is not found, cannot be parsed or is negative.
+//
+// Config file example (config.yaml):
+//
+//	storage:
+//	  quota: 10EiB
+//
+// Environment variable override:
+//
+//	export STORAGE_QUOTA=12EiB
+//
+// Usage:
+//
+//	quota := config.GetBytesUint64("storage.quota")
+func GetBytesUint64(key string) uint64 {
+	return std.GetBytesUint64(key)
+}
+
+// GetBytesUint64 is like [GetBytesUint64] but reads from c.
+func (c *Config) GetBytesUint64(key string) uint64 {
+	return c.Snapshot().GetBytesUint64(key)
+}
+
+// GetBytesUint64 is like [GetBytesUint64] but reads from s.
+func (s *Snapshot) GetBytesUint64(key string) uint64 {
+	if val, ok := s.getEnvValue(key); ok {
+		return toBytesUint64(val)
+	}
+	if val, ok := s.getFromMap(key); ok {
+		return toBytesUint64(val)
+	}
+	return 0
+}
+
+// GetBytesUint64Or returns the size in bytes associated with the given key,
+// as a uint64, or defaultValue if the key is not set.
+//
+// Usage:
+//
+//	quota := config.GetBytesUint64Or("storage.quota", 1<<40)
+func GetBytesUint64Or(key string, defaultValue uint64) uint64 {
+	return std.GetBytesUint64Or(key, defaultValue)
+}
+
+// GetBytesUint64Or is like [GetBytesUint64Or] but reads from c.
+func (c *Config) GetBytesUint64Or(key string, defaultValue uint64) uint64 {
+	return c.Snapshot().GetBytesUint64Or(key, defaultValue)
+}
+
+// GetBytesUint64Or is like [GetBytesUint64Or] but reads from s.
+func (s *Snapshot) GetBytesUint64Or(key string, defaultValue uint64) uint64 {
+	if s.IsSet(key) {
+		return s.GetBytesUint64(key)
+	}
+	return defaultValue
+}
+
+// GetPercent returns the fraction associated with the given key, written as
+// a percentage (e.g., 25% returns 0.25).
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE)
+//  2. Config file value
+//
+// Returns 0 if the key is not found or cannot be parsed (see [ParsePercent]).
+// Numbers without a % sign are returned as is.
+//
+// Config file example (config.yaml):
+//
+//	tracing:
+//	  sample_rate: 25%
+//
+// Environment variable override:
+//
+//	export TRACING_SAMPLE_RATE=5%
+//
+// Usage:
+//
+//	if rand.Float64() < config.GetPercent("tracing.sample_rate") {
+//	    span.Sample()
+//	}
+func GetPercent(key string) float64 {
+	return std.GetPercent(key)
+}
+
+// GetPercent is like [GetPercent] but reads from c.
+func (c *Config) GetPercent(key string) float64 {
+	return c.Snapshot().GetPercent(key)
+}
+
+// GetPercent is like [GetPercent] but reads from s.
+func (s *Snapshot) GetPercent(key string) float64 {
+	if val, ok := s.getEnvValue(key); ok {
+		return toPercent(val)
+	}
+	if val, ok := s.getFromMap(key); ok {
+		return toPercent(val)
+	}
+	return 0
+}
+
+// GetPercentOr returns the fraction associated with the given key, or
+// defaultValue if the key is not set.
+//
+// Usage:
+//
+//	sampleRate := config.GetPercentOr("tracing.sample_rate", 0.01)
+func GetPercentOr(key string, defaultValue float64) float64 {
+	return std.GetPercentOr(key, defaultValue)
+}
+
+// GetPercentOr is like [GetPercentOr] but reads from c.
+func (c *Config) GetPercentOr(key string, defaultValue float64) float64 {
+	return c.Snapshot().GetPercentOr(key, defaultValue)
+}
+
+// GetPercentOr is like [GetPercentOr] but reads from s.
+func (s *Snapshot) GetPercentOr(key string, defaultValue float64) float64 {
+	if s.IsSet(key) {
+		return s.GetPercent(key)
+	}
+	return defaultValue
+}
+
+// GetRate returns the [Rate] associated with the given key.
+//
+// Lookup order:
+//  1. Environment variable (key converted to UPPER_SNAKE_CASE)
+//  2. Config file value
+//
+// Returns the zero Rate if the key is not found or cannot be parsed (see
+// [ParseRate]).
+//
+// Config file example (config.yaml):
+//
+//	ratelimit:
+//	  requests: 100/s
+//	  signups: 10/h
+//
+// Environment variable override:
+//
+//	export RATELIMIT_REQUESTS=500/s
+//
+// Usage:
+//
+//	r := config.GetRate("ratelimit.requests")
+//	limiter := rate.NewLimiter(rate.Limit(r.PerSecond()), 10)
+func GetRate(key string) Rate {
+	return std.GetRate(key)
+}
+
+// GetRate is like [GetRate] but reads from c.
+func (c *Config) GetRate(key string) Rate {
+	return c.Snapshot().GetRate(key)
+}
+
+// GetRate is like [GetRate] but reads from s.
+func (s *Snapshot) GetRate(key string) Rate {
+	if val, ok := s.getEnvValue(key); ok {
+		return toRate(val)
+	}
+	if val, ok := s.getFromMap(key); ok {
+		return toRate(val)
+	}
+	return Rate{}
+}
+
+// GetRateOr returns the [Rate] associated with the given key, or
+// defaultValue if the key is not set.
+//
+// Usage:
+//
+//	rate := config.GetRateOr("ratelimit.requests", config.Rate{Count: 100, Per: time.Second})
+func GetRateOr(key string, defaultValue Rate) Rate {
+	return std.GetRateOr(key, defaultValue)
+}
+
+// GetRateOr is like [GetRateOr] but reads from c.
+func (c *Config) GetRateOr(key string, defaultValue Rate) Rate {
+	return c.Snapshot().GetRateOr(key, defaultValue)
+}
+
+// GetRateOr is like [GetRateOr] but reads from s.
+func (s *Snapshot) GetRateOr(key string, defaultValue Rate) Rate {
+	if s.IsSet(key) {
+		return s.GetRate(key)
+	}
+	return defaultValue
+}
+
+func toBytes(v any) int64 {
+	switch val := v.(type) {
+	case ByteSize:
+		return int64(val)
+	case int:
+		return int64(val)
+	case int64:
+		return val
+	case uint64:
+		return int64(val)
+	case float64:
+		return int64(val)
+	case string:
+		b, _ := ParseByteSize(val)
+		return int64(b)
+	default:
+		return 0
+	}
+}
+
+func toBytesUint64(v any) uint64 {
+	switch val := v.(type) {
+	case ByteSize:
+		return uint64(max(val, 0))
+	case int:
+		return uint64(max(val, 0))
+	case int64:
+		return uint64(max(val, 0))
+	case uint64:
+		return val
+	case float64:
+		if val < 0 || val >= math.MaxUint64 {
+			return 0
+		}
+		return uint64(val)
+	case string:
+		b, _ := parseBytes(val, math.MaxUint64)
+		return b
+	default:
+		return 0
+	}
+}
+
+func toPercent(v any) float64 {
+	switch val := v.(type) {
+	case Percent:
+		return float64(val)
+	case string:
+		p, _ := ParsePercent(val)
+		return float64(p)
+	default:
+		return toFloat64(v)
+	}
+}
+
+func toRate(v any) Rate {
+	switch val := v.(type) {
+	case Rate:
+		return val
+	case string:
+		r, _ := ParseRate(val)
+		return r
+	default:
+		return Rate{}
+	}
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"flag"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := map[string]ByteSize{
+		"1024":    1024,
+		"512MB":   512_000_000,
+		"512 mb":  512_000_000,
+		"10MiB":   10 << 20,
+		"1.5GiB":  3 << 29,
+		"100k":    100_000,
+		"64Ki":    64 << 10,
+		"2TB":     2_000_000_000_000,
+		"1B":      1,
+		" 4 GiB ": 4 << 30,
+	}
+	for s, want := range tests {
+		got, err := ParseByteSize(s)
+		if err != nil || got != want {
+			t.Errorf("ParseByteSize(%q) = %d, %v, want %d", s, got, err, want)
+		}
+	}
+
+	for _, s := range []string{"", "MB", "-5MB", "10XB", "10EiB"} {
+		if _, err := ParseByteSize(s); err == nil {
+			t.Errorf("ParseByteSize(%q) error = nil, want error", s)
+		}
+	}
+
+	if got := ByteSize(10 << 20).String(); got != "10MiB" {
+		t.Errorf("String() = %q, want %q", got, "10MiB")
+	}
+	if got := ByteSize(1500).String(); got != "1500B" {
+		t.Errorf("String() = %q, want %q", got, "1500B")
+	}
+}
+
+func TestParsePercent(t *testing.T) {
+	tests := map[string]Percent{
+		"25%":   0.25,
+		"12.5%": 0.125,
+		"150 %": 1.5,
+		"0.25":  0.25,
+	}
+	for s, want := range tests {
+		got, err := ParsePercent(s)
+		if err != nil || got != want {
+			t.Errorf("ParsePercent(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	if _, err := ParsePercent("lots%"); err == nil {
+		t.Error("ParsePercent(lots%) error = nil, want error")
+	}
+	if got := Percent(0.25).String(); got != "25%" {
+		t.Errorf("String() = %q, want %q", got, "25%")
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := map[string]Rate{
+		"100/s":     {100, time.Second},
+		"5000/hour": {5000, time.Hour},
+		"1.5/min":   {1.5, time.Minute},
+		"10/500ms":  {10, 500 * time.Millisecond},
+		"2 / day":   {2, 24 * time.Hour},
+		"60/Minute": {60, time.Minute},
+		"1/1m30s":   {1, 90 * time.Second},
+	}
+	for s, want := range tests {
+		got, err := ParseRate(s)
+		if err != nil || got != want {
+			t.Errorf("ParseRate(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"100", "x/s", "-1/s", "10/fortnight", "10/0s"} {
+		if _, err := ParseRate(s); err == nil {
+			t.Errorf("ParseRate(%q) error = nil, want error", s)
+		}
+	}
+
+	r := Rate{Count: 10, Per: time.Second}
+	if r.PerSecond() != 10 || r.Interval() != 100*time.Millisecond {
+		t.Errorf("PerSecond() = %v, Interval() = %v, want 10, 100ms", r.PerSecond(), r.Interval())
+	}
+	if (Rate{}).PerSecond() != 0 || (Rate{}).Interval() != 0 {
+		t.Error("zero Rate has a non-zero PerSecond() or Interval()")
+	}
+	if got := (Rate{Count: 10, Per: 500 * time.Millisecond}).String(); got != "10/500ms" {
+		t.Errorf("String() = %q, want %q", got, "10/500ms")
+	}
+}
+
+func TestUnitGetters(t *testing.T) {
+	t.Run("config file values", func(t *testing.T) {
+		Reset()
+		Set("http.max_body", "10MiB")
+		Set("cache.size", 4096)
+		Set("tracing.sample_rate", "25%")
+		Set("ratelimit.requests", "100/s")
+
+		if got := GetBytes("http.max_body"); got != 10<<20 {
+			t.Errorf("GetBytes(http.max_body) = %d, want %d", got, 10<<20)
+		}
+		if got := GetBytes("cache.size"); got != 4096 {
+			t.Errorf("GetBytes(cache.size) = %d, want %d", got, 4096)
+		}
+		if got := GetPercent("tracing.sample_rate"); got != 0.25 {
+			t.Errorf("GetPercent(tracing.sample_rate) = %v, want %v", got, 0.25)
+		}
+		if got := GetRate("ratelimit.requests"); got != (Rate{100, time.Second}) {
+			t.Errorf("GetRate(ratelimit.requests) = %v, want 100/s", got)
+		}
+	})
+
+	t.Run("environment variables", func(t *testing.T) {
+		Reset()
+		Set("http.max_body", "10MiB")
+		os.Setenv("HTTP_MAX_BODY", "1GB")
+		defer os.Unsetenv("HTTP_MAX_BODY")
+		os.Setenv("RATELIMIT_REQUESTS", "5/m")
+		defer os.Unsetenv("RATELIMIT_REQUESTS")
+
+		if got := GetBytes("http.max_body"); got != 1e9 {
+			t.Errorf("GetBytes(http.max_body) = %d, want %d", got, int64(1e9))
+		}
+		if got := GetRate("ratelimit.requests"); got != (Rate{5, time.Minute}) {
+			t.Errorf("GetRate(ratelimit.requests) = %v, want 5/m", got)
+		}
+	})
+
+	t.Run("uint64 sizes", func(t *testing.T) {
+		Reset()
+		Set("storage.quota", "15EiB")
+		Set("storage.block", 4096)
+		Set("storage.negative", -1)
+		os.Setenv("STORAGE_MAX", "18446744073709551615")
+		defer os.Unsetenv("STORAGE_MAX")
+
+		if got := GetBytesUint64("storage.quota"); got != 15<<60 {
+			t.Errorf("GetBytesUint64(storage.quota) = %d, want %d", got, uint64(15<<60))
+		}
+		if got := GetBytes("storage.quota"); got != 0 {
+			t.Errorf("GetBytes(storage.quota) = %d, want 0 (out of range)", got)
+		}
+		if got := GetBytesUint64("storage.block"); got != 4096 {
+			t.Errorf("GetBytesUint64(storage.block) = %d, want %d", got, 4096)
+		}
+		if got := GetBytesUint64("storage.max"); got != math.MaxUint64 {
+			t.Errorf("GetBytesUint64(storage.max) = %d, want %d", got, uint64(math.MaxUint64))
+		}
+		if got := GetBytesUint64("storage.negative"); got != 0 {
+			t.Errorf("GetBytesUint64(storage.negative) = %d, want 0", got)
+		}
+		if got := GetBytesUint64Or("storage.missing", 1<<40); got != 1<<40 {
+			t.Errorf("GetBytesUint64Or(storage.missing) = %d, want %d", got, 1<<40)
+		}
+		if got := GetBytesUint64Or("storage.block", 1<<40); got != 4096 {
+			t.Errorf("GetBytesUint64Or(storage.block) = %d, want %d", got, 4096)
+		}
+	})
+
+	t.Run("Or variants", func(t *testing.T) {
+		Reset()
+		Set("cache.size", "0")
+
+		if got := GetBytesOr("cache.size", 100); got != 0 {
+			t.Errorf("GetBytesOr(cache.size) = %d, want %d", got, 0)
+		}
+		if got := GetBytesOr("http.max_body", 1<<20); got != 1<<20 {
+			t.Errorf("GetBytesOr(http.max_body) = %d, want %d", got, 1<<20)
+		}
+		if got := GetPercentOr("tracing.sample_rate", 0.01); got != 0.01 {
+			t.Errorf("GetPercentOr(tracing.sample_rate) = %v, want %v", got, 0.01)
+		}
+		want := Rate{Count: 1, Per: time.Second}
+		if got := GetRateOr("ratelimit.requests", want); got != want {
+			t.Errorf("GetRateOr(ratelimit.requests) = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		Reset()
+		Set("limits", map[string]any{"max_body": "10MiB", "sample_rate": "5%", "requests": "100/s"})
+		os.Setenv("LIMITS_REQUESTS", "20/s")
+		defer os.Unsetenv("LIMITS_REQUESTS")
+
+		var cfg struct {
+			MaxBody    ByteSize `yaml:"max_body"`
+			SampleRate Percent  `yaml:"sample_rate"`
+			Requests   Rate     `yaml:"requests"`
+		}
+		if err := UnmarshalKey("limits", &cfg); err != nil {
+			t.Fatalf("UnmarshalKey() error = %v", err)
+		}
+		if cfg.MaxBody != 10<<20 || cfg.SampleRate != 0.05 || cfg.Requests != (Rate{20, time.Second}) {
+			t.Errorf("UnmarshalKey() = %+v", cfg)
+		}
+
+		Set("limits.sample_rate", "lots")
+		if err := UnmarshalKey("limits", &cfg); err == nil {
+			t.Error("UnmarshalKey() of an invalid percentage error = nil, want error")
+		}
+	})
+
+	t.Run("flags", func(t *testing.T) {
+		Reset()
+		var cfg struct {
+			MaxBody ByteSize `yaml:"max_body"`
+		}
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		BindFlags(fs, &cfg)
+		if err := fs.Parse([]string{"--max_body=2MiB"}); err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if got := GetBytes("max_body"); got != 2<<20 {
+			t.Errorf("GetBytes(max_body) = %d, want %d", got, 2<<20)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// tagResolver replaces the values tagged with !env, !file, !base64 or
+// !duration with plain YAML values.
+//
+// Supported tags:
+//   - !env NAME: the value of the environment variable NAME in the environment
+//     of the Config, typed like an untagged value
+//   - !file path: the content of a file, resolved relative to the file containing the tag
+//   - !base64 data: the decoded bytes of standard base64 data, as a string
+//     that [Unmarshal] also decodes into []byte fields
+//   - !duration 5m: a time.Duration (see [ParseDuration])
+//
+// Config file example (config.yaml):
+//
+//	database:
+//	  host: !env DB_HOST
+//	  ca_cert: !file ./certs/ca.pem
+//	  key: !base64 c2VjcmV0
+//	  timeout: !duration 5s
+type tagResolver struct {
+	files  map[*yaml.Node]string       // file of included nodes (see includeResolver)
+	lookup func(string) (string, bool) // environment variables of !env tags
+	values []typedValue                // values YAML cannot represent, set after decoding
+}
+
+// typedValue is a value to set at path (map keys and sequence indexes) of a
+// decoded document
+type typedValue struct {
+	path  []any
+	value any
+}
+
+// resolve replaces the tagged values under node, which belongs to file.
+// Errors report the file and line of the tagged value.
+func (r *tagResolver) resolve(node *yaml.Node, file string, path []any) error {
+	if f, ok := r.files[node]; ok {
+		file = f
+	}
+
+	switch node.Tag {
+	case "!env", "!file", "!base64", "!duration":
+		if node.Kind != yaml.ScalarNode {
+			return fmt.Errorf("%s:%d: %s requires a scalar value", file, node.Line, node.Tag)
+		}
+		if err := r.resolveScalar(node, file, path); err != nil {
+			return fmt.Errorf("%s:%d: %s: %w", file, node.Line, node.Tag, err)
+		}
+		return nil
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if err := r.resolve(child, file, path); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			if err := r.resolve(child, file, append(path[:len(path):len(path)], i)); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			key := node.Content[i-1].Value
+			if err := r.resolve(node.Content[i], file, append(path[:len(path):len(path)], key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveScalar replaces the value of a tagged scalar node
+func (r *tagResolver) resolveScalar(node *yaml.Node, file string, path []any) error {
+	value := strings.TrimSpace(node.Value)
+
+	switch node.Tag {
+	case "!env":
+		env, ok := r.lookup(value)
+		if !ok {
+			return fmt.Errorf("environment variable %s is not set", value)
+		}
+		// Let YAML type the value, e.g. as an int for "5432"
+		node.Tag, node.Style, node.Value = "", 0, env
+	case "!file":
+		if !filepath.IsAbs(value) {
+			value = filepath.Join(filepath.Dir(file), value)
+		}
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return err
+		}
+		node.Tag, node.Value = "!!str", string(data)
+	case "!base64":
+		data, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return err
+		}
+		node.Tag, node.Value = "!!str", string(data)
+	case "!duration":
+		d, err := ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		node.Tag, node.Value = "!!str", value
+		r.values = append(r.values, typedValue{path: path, value: d})
+	}
+	return nil
+}
+
+// apply sets the typed values in data, the decoded document
+func (r *tagResolver) apply(data map[string]any) {
+values:
+	for _, v := range r.values {
+		var current any = data
+		for i, step := range v.path {
+			last := i == len(v.path)-1
+			switch step := step.(type) {
+			case string:
+				m, ok := current.(map[string]any)
+				if !ok {
+					continue values
+				}
+				if last {
+					m[step] = v.value
+				}
+				current = m[step]
+			case int:
+				s, ok := current.([]any)
+				if !ok || step >= len(s) {
+					continue values
+				}
+				if last {
+					s[step] = v.value
+				}
+				current = s[step]
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestYAMLTags(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "ca.pem", "-----BEGIN CERTIFICATE-----\n")
+
+	t.Run("tags are resolved", func(t *testing.T) {
+		Reset()
+		os.Setenv("TEST_TAGS_DB_PORT", "5433")
+		defer os.Unsetenv("TEST_TAGS_DB_PORT")
+
+		path := writeTestFile(t, dir, "config.yaml", strings.Join([]string{
+			"database:",
+			"  port: !env TEST_TAGS_DB_PORT",
+			"  ca_cert: !file ./ca.pem",
+			"  key: !base64 c2VjcmV0",
+			"  timeout: !duration 5s",
+			"",
+		}, "\n"))
+
+		cfg, err := New(WithSources(File(path)))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		if got := cfg.AllSettings()["database"].(map[string]any)["port"]; got != 5433 {
+			t.Errorf("database.port = %#v, want %#v", got, 5433)
+		}
+		if got := cfg.GetString("database.ca_cert"); got != "-----BEGIN CERTIFICATE-----\n" {
+			t.Errorf("GetString(database.ca_cert) = %q", got)
+		}
+		if got := cfg.GetString("database.key"); got != "secret" {
+			t.Errorf("GetString(database.key) = %q, want %q", got, "secret")
+		}
+
+		var db struct {
+			Key     []byte        `yaml:"key"`
+			Timeout time.Duration `yaml:"timeout"`
+		}
+		if err := cfg.UnmarshalKey("database", &db); err != nil {
+			t.Fatalf("UnmarshalKey() error = %v", err)
+		}
+		if !reflect.DeepEqual(db.Key, []byte("secret")) {
+			t.Errorf("Key = %q, want %q", db.Key, "secret")
+		}
+
+		var all struct {
+			Database struct {
+				Key string `yaml:"key"`
+			} `yaml:"database"`
+		}
+		if err := cfg.Unmarshal(&all); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		if all.Database.Key != "secret" {
+			t.Errorf("Database.Key = %q, want %q", all.Database.Key, "secret")
+		}
+		if db.Timeout != 5*time.Second {
+			t.Errorf("Timeout = %v, want %v", db.Timeout, 5*time.Second)
+		}
+	})
+
+	t.Run("files are relative to the including file", func(t *testing.T) {
+		Reset()
+		if err := os.MkdirAll(dir+"/shared", 0755); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		writeTestFile(t, dir, "shared/tls.yaml", "ca_cert: !file ../ca.pem\ntimeout: !duration 1m\n")
+		path := writeTestFile(t, dir, "config.yaml", "tls: !include shared/tls.yaml\n")
+
+		cfg, err := New(WithSources(File(path)))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if got := cfg.GetString("tls.ca_cert"); got != "-----BEGIN CERTIFICATE-----\n" {
+			t.Errorf("GetString(tls.ca_cert) = %q", got)
+		}
+		if got := cfg.GetDuration("tls.timeout"); got != time.Minute {
+			t.Errorf("GetDuration(tls.timeout) = %v, want %v", got, time.Minute)
+		}
+	})
+
+	t.Run("env of the Config", func(t *testing.T) {
+		Reset()
+		os.Setenv("TEST_TAGS_DB_HOST", "fromproc")
+		defer os.Unsetenv("TEST_TAGS_DB_HOST")
+		path := writeTestFile(t, dir, "config.yaml", "database:\n  host: !env TEST_TAGS_DB_HOST\n")
+
+		cfg, err := New(WithSources(File(path), EnvMap(map[string]string{"TEST_TAGS_DB_HOST": "fromenvmap"})))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if got := cfg.GetString("database.host"); got != "fromenvmap" {
+			t.Errorf("GetString(database.host) = %q, want %q", got, "fromenvmap")
+		}
+	})
+
+	t.Run("errors report the line", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			content string
+		}{
+			{"unset env", "app:\n  name: x\n  host: !env TEST_TAGS_UNSET\n"},
+			{"missing file", "app:\n  name: x\n  cert: !file ./missing.pem\n"},
+			{"invalid base64", "app:\n  name: x\n  key: !base64 '!!!'\n"},
+			{"invalid duration", "app:\n  name: x\n  timeout: !duration 5 minutes\n"},
+			{"non-scalar", "app:\n  name: x\n  timeout: !duration [1]\n"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				Reset()
+				path := writeTestFile(t, dir, "config.yaml", tt.content)
+
+				_, err := New(WithSources(File(path)))
+				if err == nil || !strings.Contains(err.Error(), "config.yaml:3") {
+					t.Errorf("New() error = %v, want error at config.yaml:3", err)
+				}
+			})
+		}
+	})
+}